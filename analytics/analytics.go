@@ -0,0 +1,155 @@
+// Package analytics measures the on-time performance of tracked WorkWave
+// routes: how the actual arrival at each stop compares with the plan and with
+// the customer's time windows, and how long drivers spent at each stop.
+package analytics
+
+import (
+	"time"
+
+	workwave "github.com/tkh/go-workwave"
+)
+
+// Stop is the performance of a single route step serving an order.
+type Stop struct {
+	TerritoryID string
+	RouteID     string
+	Date        workwave.Date
+	// DriverID and VehicleID are those which served the stop according to
+	// its tracking data, or those of the route if it was not tracked.
+	DriverID  string
+	VehicleID string
+	OrderID   string
+	Type      workwave.StepType
+	Status    workwave.TrackingStatus
+
+	// PlannedArrival is the arrival planned by WorkWave, and ActualArrival
+	// the arrival reported by the driver or otherwise detected from GPS
+	// positions. ActualArrival is not set if the stop was not reached.
+	PlannedArrival workwave.SecOfDay
+	ActualArrival  workwave.SecOfDay
+	// ArrivalDelta is ActualArrival less PlannedArrival, which is positive
+	// when the stop was reached later than planned.
+	ArrivalDelta time.Duration
+
+	// TimeWindows are the time windows of the order step on the route date.
+	TimeWindows []workwave.TimeWindow
+	// Lateness is how far ActualArrival is outside of the nearest time
+	// window: positive when late, negative when early, and zero when within
+	// a window or when the stop has no time windows.
+	Lateness time.Duration
+
+	// Dwell is the time spent at the stop, from arrival to departure, and
+	// ServiceTime the time planned to be spent there. Dwell is zero unless
+	// HasDwell.
+	Dwell       time.Duration
+	HasDwell    bool
+	ServiceTime time.Duration
+}
+
+// Reached reports whether the actual arrival at the stop is known.
+func (s Stop) Reached() bool {
+	return s.ActualArrival.IsSet()
+}
+
+// Windowed reports whether the stop was reached and has time windows, so its
+// punctuality can be measured.
+func (s Stop) Windowed() bool {
+	return s.Reached() && len(s.TimeWindows) > 0
+}
+
+// OnTime reports whether the stop was reached within one of its time windows.
+func (s Stop) OnTime() bool {
+	return s.Windowed() && s.Lateness == 0
+}
+
+// DwellDelta is Dwell less ServiceTime, which is positive when the driver
+// spent longer at the stop than planned.
+func (s Stop) DwellDelta() time.Duration {
+	return s.Dwell - s.ServiceTime
+}
+
+// Stops measures the steps of p which serve orders. The orders are needed to
+// measure lateness and dwell, so steps whose order is unknown have neither
+// time windows nor service time.
+func Stops(territoryID string, p workwave.RoutePlan) []Stop {
+	var stops []Stop
+	for _, s := range p.Steps {
+		if s.OrderID == "" {
+			continue
+		}
+
+		st := Stop{
+			TerritoryID:    territoryID,
+			RouteID:        p.Route.ID,
+			Date:           p.Route.Date,
+			DriverID:       p.Route.DriverID,
+			VehicleID:      p.Route.VehicleID,
+			OrderID:        s.OrderID,
+			Type:           s.Type,
+			PlannedArrival: s.ArrivalSec,
+			ActualArrival:  -1,
+		}
+		if s.OrderStep != nil {
+			st.TimeWindows = s.OrderStep.TimeWindowsOn(p.Route.Date)
+			st.ServiceTime = time.Duration(s.OrderStep.ServiceTimeSec) * time.Second
+		}
+
+		if td := s.TrackingData; td != nil {
+			if td.DriverID != "" {
+				st.DriverID = td.DriverID
+			}
+			if td.VehicleID != "" {
+				st.VehicleID = td.VehicleID
+			}
+			st.Status = td.Status
+			st.ActualArrival = firstSet(td.TimeInSec, td.TimeInDetectedSec)
+			if out := firstSet(td.TimeOutSec, td.TimeOutDetectedSec); st.Reached() && out.IsSet() && out >= st.ActualArrival {
+				st.Dwell = out.Sub(st.ActualArrival)
+				st.HasDwell = true
+			}
+		}
+		if st.Reached() {
+			st.ArrivalDelta = st.ActualArrival.Sub(st.PlannedArrival)
+			st.Lateness = lateness(st.ActualArrival, st.TimeWindows)
+		}
+		stops = append(stops, st)
+	}
+	return stops
+}
+
+func firstSet(secs ...workwave.SecOfDay) workwave.SecOfDay {
+	for _, s := range secs {
+		if s.IsSet() {
+			return s
+		}
+	}
+	return -1
+}
+
+// lateness returns how far t is outside of the nearest of tws, which are in
+// chronological order.
+func lateness(t workwave.SecOfDay, tws []workwave.TimeWindow) time.Duration {
+	var nearest time.Duration
+	for n, tw := range tws {
+		var d time.Duration
+		switch {
+		case t < tw.StartSec:
+			d = t.Sub(tw.StartSec)
+		case t > tw.EndSec:
+			d = t.Sub(tw.EndSec)
+		default:
+			return 0
+		}
+		if n == 0 || abs(d) < abs(nearest) {
+			nearest = d
+		}
+	}
+	return nearest
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
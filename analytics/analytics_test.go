@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+	"github.com/tkh/go-workwave/internal/fixture"
+)
+
+// fixturePlans returns the routes of the current routes fixture, which have
+// been partly tracked, sorted by ID and joined with their orders, drivers
+// and vehicles.
+func fixturePlans(c *qt.C) []workwave.RoutePlan {
+	return fixture.Load(c, "routes-list-current.json").Plans(c)
+}
+
+func TestStops(t *testing.T) {
+	c := qt.New(t)
+	plans := fixturePlans(c)
+
+	stops := Stops("territory", plans[0])
+	c.Assert(stops, qt.HasLen, 2)
+	c.Assert(stops[0], qt.DeepEquals, Stop{
+		TerritoryID:    "territory",
+		RouteID:        "0d8855e6-28a0-4e89-9c67-b44c66c39ba6-20151204",
+		Date:           workwave.NewDate(2015, time.December, 4),
+		DriverID:       "a08213e6-673f-4efc-955e-2bf587813162",
+		VehicleID:      "0d8855e6-28a0-4e89-9c67-b44c66c39ba6",
+		OrderID:        "49269a16-479c-4531-8ffd-513b7ccd0621",
+		Type:           workwave.StepDelivery,
+		Status:         workwave.TrackingDone,
+		PlannedArrival: 34200,
+		ActualArrival:  34526,
+		ArrivalDelta:   326 * time.Second,
+		TimeWindows:    []workwave.TimeWindow{{StartSec: 30600, EndSec: 37800}, {StartSec: 45000, EndSec: 55800}},
+		// The time out was only detected.
+		Dwell:       1409 * time.Second,
+		HasDwell:    true,
+		ServiceTime: 10 * time.Minute,
+	})
+	c.Assert(stops[0].OnTime(), qt.Equals, true)
+	c.Assert(stops[0].DwellDelta(), qt.Equals, 809*time.Second)
+	c.Assert(stops[1].Windowed(), qt.Equals, false)
+	c.Assert(stops[1].Dwell, qt.Equals, 1120*time.Second)
+
+	stops = Stops("territory", plans[1])
+	c.Assert(stops, qt.HasLen, 2)
+	c.Assert(stops[0].Reached(), qt.Equals, false)
+	c.Assert(stops[0].ArrivalDelta, qt.Equals, time.Duration(0))
+	c.Assert(stops[0].HasDwell, qt.Equals, false)
+}
+
+func TestLateness(t *testing.T) {
+	c := qt.New(t)
+	tws := []workwave.TimeWindow{
+		{StartSec: workwave.NewSecOfDay(9, 0, 0), EndSec: workwave.NewSecOfDay(11, 0, 0)},
+		{StartSec: workwave.NewSecOfDay(14, 0, 0), EndSec: workwave.NewSecOfDay(16, 0, 0)},
+	}
+	for _, test := range []struct {
+		arrival workwave.SecOfDay
+		want    time.Duration
+	}{
+		{workwave.NewSecOfDay(8, 45, 0), -15 * time.Minute},
+		{workwave.NewSecOfDay(9, 0, 0), 0},
+		{workwave.NewSecOfDay(11, 0, 0), 0},
+		{workwave.NewSecOfDay(11, 30, 0), 30 * time.Minute},
+		// Between windows, the nearest one counts.
+		{workwave.NewSecOfDay(13, 0, 0), -time.Hour},
+		{workwave.NewSecOfDay(15, 0, 0), 0},
+		{workwave.NewSecOfDay(17, 5, 0), 65 * time.Minute},
+	} {
+		c.Check(lateness(test.arrival, tws), qt.Equals, test.want, qt.Commentf("arrival %s", test.arrival))
+	}
+	c.Assert(lateness(workwave.NewSecOfDay(23, 0, 0), nil), qt.Equals, time.Duration(0))
+}
+
+func TestStopsTimeWindowException(t *testing.T) {
+	c := qt.New(t)
+	d := workwave.NewDate(2015, time.December, 4)
+	delivery := &workwave.OrderStep{
+		TimeWindows:          []workwave.TimeWindow{{StartSec: 30600, EndSec: 37800}},
+		TimeWindowExceptions: map[workwave.Date]workwave.TimeWindow{d: {StartSec: 45000, EndSec: 55800}},
+	}
+	stops := Stops("territory", workwave.RoutePlan{
+		Route: workwave.Route{ID: "r1", Date: d, DriverID: "route driver"},
+		Steps: []workwave.PlanStep{
+			{RouteStep: workwave.RouteStep{Type: workwave.StepDeparture}},
+			{
+				RouteStep: workwave.RouteStep{
+					Type:       workwave.StepDelivery,
+					OrderID:    "o1",
+					ArrivalSec: 34200,
+					TrackingData: &workwave.TrackingData{
+						DriverID:           "substitute",
+						TimeInSec:          -1,
+						TimeInDetectedSec:  34200,
+						TimeOutSec:         -1,
+						TimeOutDetectedSec: -1,
+					},
+				},
+				Order:     &workwave.Order{ID: "o1", Delivery: delivery},
+				OrderStep: delivery,
+			},
+		},
+	})
+	c.Assert(stops, qt.HasLen, 1)
+	c.Assert(stops[0].DriverID, qt.Equals, "substitute")
+	c.Assert(stops[0].ActualArrival, qt.Equals, workwave.SecOfDay(34200))
+	c.Assert(stops[0].Lateness, qt.Equals, -10800*time.Second)
+	c.Assert(stops[0].HasDwell, qt.Equals, false)
+}
+
+func TestStopsPartialTracking(t *testing.T) {
+	c := qt.New(t)
+	var step workwave.RouteStep
+	err := json.Unmarshal([]byte(`{
+		"type": "delivery",
+		"orderId": "o1",
+		"arrivalSec": 34200,
+		"trackingData": {"timeInDetectedSec": 36000, "status": "done"}
+	}`), &step)
+	c.Assert(err, qt.IsNil)
+
+	delivery := &workwave.OrderStep{TimeWindows: []workwave.TimeWindow{{StartSec: 30600, EndSec: 37800}}}
+	stops := Stops("territory", workwave.RoutePlan{
+		Route: workwave.Route{ID: "r1"},
+		Steps: []workwave.PlanStep{{
+			RouteStep: step,
+			Order:     &workwave.Order{ID: "o1", Delivery: delivery},
+			OrderStep: delivery,
+		}},
+	})
+	c.Assert(stops, qt.HasLen, 1)
+	c.Assert(stops[0].ActualArrival, qt.Equals, workwave.SecOfDay(36000))
+	c.Assert(stops[0].ArrivalDelta, qt.Equals, 30*time.Minute)
+	c.Assert(stops[0].OnTime(), qt.Equals, true)
+	c.Assert(stops[0].HasDwell, qt.Equals, false)
+}
@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"time"
+)
+
+// Summary aggregates the performance of a set of stops.
+type Summary struct {
+	Stops   int // all stops
+	Reached int // stops whose actual arrival is known
+	// Windowed stops were reached and have time windows, and are either
+	// OnTime, Early or Late.
+	Windowed int
+	OnTime   int
+	Early    int
+	Late     int
+
+	// TotalLateness and MaxLateness are over late stops, and TotalEarliness
+	// and MaxEarliness over early stops, as positive durations.
+	TotalLateness  time.Duration
+	MaxLateness    time.Duration
+	TotalEarliness time.Duration
+	MaxEarliness   time.Duration
+
+	// TotalArrivalDelta is over reached stops.
+	TotalArrivalDelta time.Duration
+	// Dwelled stops have a known dwell, over which TotalDwell and
+	// TotalDwellDelta are.
+	Dwelled         int
+	TotalDwell      time.Duration
+	TotalDwellDelta time.Duration
+}
+
+// Add includes s in the summary.
+func (sum *Summary) Add(s Stop) {
+	sum.Stops++
+	if !s.Reached() {
+		return
+	}
+	sum.Reached++
+	sum.TotalArrivalDelta += s.ArrivalDelta
+
+	if s.Windowed() {
+		sum.Windowed++
+		switch {
+		case s.Lateness > 0:
+			sum.Late++
+			sum.TotalLateness += s.Lateness
+			if s.Lateness > sum.MaxLateness {
+				sum.MaxLateness = s.Lateness
+			}
+		case s.Lateness < 0:
+			sum.Early++
+			sum.TotalEarliness -= s.Lateness
+			if -s.Lateness > sum.MaxEarliness {
+				sum.MaxEarliness = -s.Lateness
+			}
+		default:
+			sum.OnTime++
+		}
+	}
+
+	if s.HasDwell {
+		sum.Dwelled++
+		sum.TotalDwell += s.Dwell
+		sum.TotalDwellDelta += s.DwellDelta()
+	}
+}
+
+// OnTimeRate is the fraction of windowed stops which were on time, or 0 if
+// there are none.
+func (sum Summary) OnTimeRate() float64 {
+	if sum.Windowed == 0 {
+		return 0
+	}
+	return float64(sum.OnTime) / float64(sum.Windowed)
+}
+
+// MeanLateness is the mean lateness of late stops.
+func (sum Summary) MeanLateness() time.Duration {
+	return mean(sum.TotalLateness, sum.Late)
+}
+
+// MeanEarliness is the mean earliness of early stops.
+func (sum Summary) MeanEarliness() time.Duration {
+	return mean(sum.TotalEarliness, sum.Early)
+}
+
+// MeanArrivalDelta is the mean difference between the actual and planned
+// arrival of reached stops.
+func (sum Summary) MeanArrivalDelta() time.Duration {
+	return mean(sum.TotalArrivalDelta, sum.Reached)
+}
+
+// MeanDwell is the mean dwell of stops whose dwell is known.
+func (sum Summary) MeanDwell() time.Duration {
+	return mean(sum.TotalDwell, sum.Dwelled)
+}
+
+// MeanDwellDelta is the mean difference between the dwell and service time
+// of stops whose dwell is known.
+func (sum Summary) MeanDwellDelta() time.Duration {
+	return mean(sum.TotalDwellDelta, sum.Dwelled)
+}
+
+func mean(total time.Duration, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+	return total / time.Duration(n)
+}
+
+// Key groups stops for Summarize.
+type Key func(Stop) string
+
+// Keys grouping stops by driver, vehicle, route date (as yyyyMMdd) and
+// territory.
+var (
+	ByDriver    Key = func(s Stop) string { return s.DriverID }
+	ByVehicle   Key = func(s Stop) string { return s.VehicleID }
+	ByDay       Key = func(s Stop) string { return s.Date.String() }
+	ByTerritory Key = func(s Stop) string { return s.TerritoryID }
+)
+
+// Summarize aggregates stops grouped by key.
+func Summarize(stops []Stop, key Key) map[string]*Summary {
+	sums := make(map[string]*Summary)
+	for _, s := range stops {
+		k := key(s)
+		if sums[k] == nil {
+			sums[k] = &Summary{}
+		}
+		sums[k].Add(s)
+	}
+	return sums
+}
+
+// Total aggregates all of stops.
+func Total(stops []Stop) Summary {
+	var sum Summary
+	for _, s := range stops {
+		sum.Add(s)
+	}
+	return sum
+}
@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+func TestSummary(t *testing.T) {
+	c := qt.New(t)
+	window := []workwave.TimeWindow{{StartSec: 0, EndSec: 3600}}
+	stops := []Stop{
+		{DriverID: "d1", ActualArrival: 600, ArrivalDelta: time.Minute, TimeWindows: window, Dwell: 10 * time.Minute, HasDwell: true, ServiceTime: 5 * time.Minute},
+		{DriverID: "d1", ActualArrival: 4200, ArrivalDelta: 3 * time.Minute, TimeWindows: window, Lateness: 10 * time.Minute},
+		{DriverID: "d1", ActualArrival: 7200, ArrivalDelta: -time.Minute, TimeWindows: window, Lateness: 30 * time.Minute},
+		{DriverID: "d2", ActualArrival: 600, TimeWindows: window, Lateness: -20 * time.Minute},
+		{DriverID: "d2", ActualArrival: 600},
+		{DriverID: "d2", ActualArrival: -1, TimeWindows: window},
+	}
+
+	sum := Total(stops)
+	c.Assert(sum, qt.DeepEquals, Summary{
+		Stops:             6,
+		Reached:           5,
+		Windowed:          4,
+		OnTime:            1,
+		Early:             1,
+		Late:              2,
+		TotalLateness:     40 * time.Minute,
+		MaxLateness:       30 * time.Minute,
+		TotalEarliness:    20 * time.Minute,
+		MaxEarliness:      20 * time.Minute,
+		TotalArrivalDelta: 3 * time.Minute,
+		Dwelled:           1,
+		TotalDwell:        10 * time.Minute,
+		TotalDwellDelta:   5 * time.Minute,
+	})
+	c.Assert(sum.OnTimeRate(), qt.Equals, 0.25)
+	c.Assert(sum.MeanLateness(), qt.Equals, 20*time.Minute)
+	c.Assert(sum.MeanEarliness(), qt.Equals, 20*time.Minute)
+	c.Assert(sum.MeanArrivalDelta(), qt.Equals, 36*time.Second)
+	c.Assert(sum.MeanDwell(), qt.Equals, 10*time.Minute)
+	c.Assert(sum.MeanDwellDelta(), qt.Equals, 5*time.Minute)
+
+	var empty Summary
+	c.Assert(empty.OnTimeRate(), qt.Equals, 0.0)
+	c.Assert(empty.MeanLateness(), qt.Equals, time.Duration(0))
+
+	byDriver := Summarize(stops, ByDriver)
+	c.Assert(byDriver, qt.HasLen, 2)
+	c.Assert(byDriver["d1"].Stops, qt.Equals, 3)
+	c.Assert(byDriver["d1"].OnTimeRate(), qt.Equals, 1.0/3)
+	c.Assert(byDriver["d2"].Windowed, qt.Equals, 1)
+}
+
+func TestSummarizeFixture(t *testing.T) {
+	c := qt.New(t)
+	var stops []Stop
+	for _, p := range fixturePlans(c) {
+		stops = append(stops, Stops("territory", p)...)
+	}
+
+	byDay := Summarize(stops, ByDay)
+	c.Assert(byDay, qt.HasLen, 2)
+	c.Assert(byDay["20151204"].Reached, qt.Equals, 2)
+	c.Assert(byDay["20151204"].OnTime, qt.Equals, 1)
+	c.Assert(byDay["20151203"].Reached, qt.Equals, 0)
+
+	byVehicle := Summarize(stops, ByVehicle)
+	c.Assert(byVehicle["0d8855e6-28a0-4e89-9c67-b44c66c39ba6"].MeanDwellDelta(), qt.Equals, (809+1120)*time.Second/2)
+
+	byTerritory := Summarize(stops, ByTerritory)
+	c.Assert(*byTerritory["territory"], qt.DeepEquals, Total(stops))
+}
@@ -0,0 +1,254 @@
+package workwave
+
+import (
+	"fmt"
+	"time"
+)
+
+// OrderBuilder constructs an Order fluently, ie
+//
+//	o, err := NewOrder("Order 1").
+//		DeliverTo("710 7th Ave, Jasper, AL 35504, USA").
+//		Window(9*time.Hour, 11*time.Hour).
+//		EligibleOn(date).
+//		Load("pallets", 2).
+//		Build()
+//
+// Methods configuring a step, such as Window or ServiceTime, apply to the step
+// most recently started with PickupAt, PickupFromDepot, DeliverTo or
+// DeliverToDepot.
+type OrderBuilder struct {
+	order Order
+	step  *OrderStep
+	errs  ValidationErrors
+}
+
+// NewOrder starts building an Order with the given name.
+func NewOrder(name string) *OrderBuilder {
+	return &OrderBuilder{order: Order{Name: name}}
+}
+
+// EligibleOn makes the order eligible on any of the given dates.
+func (b *OrderBuilder) EligibleOn(dates ...Date) *OrderBuilder {
+	b.order.Eligibility = Eligibility{Type: EligibilityOn, OnDates: dates}
+	return b
+}
+
+// EligibleBy makes the order eligible on any date up to and including d.
+func (b *OrderBuilder) EligibleBy(d Date) *OrderBuilder {
+	b.order.Eligibility = Eligibility{Type: EligibilityBy, ByDate: &d}
+	return b
+}
+
+// EligibleAny makes the order eligible on any date.
+func (b *OrderBuilder) EligibleAny() *OrderBuilder {
+	b.order.Eligibility = Eligibility{Type: EligibilityAny}
+	return b
+}
+
+// Priority sets the priority of the order.
+func (b *OrderBuilder) Priority(p int) *OrderBuilder {
+	b.order.Priority = p
+	return b
+}
+
+// Load sets the quantity of the given load carried by the order.
+func (b *OrderBuilder) Load(key LoadDimension, n int) *OrderBuilder {
+	if b.order.Loads == nil {
+		b.order.Loads = make(Loads)
+	}
+	b.order.Loads[key] = n
+	return b
+}
+
+// Service marks the order as a service order, which has either a pickup or a
+// delivery but carries no goods between them.
+func (b *OrderBuilder) Service() *OrderBuilder {
+	b.order.IsService = true
+	return b
+}
+
+// ForceVehicle restricts the order to the vehicle with the given ID.
+func (b *OrderBuilder) ForceVehicle(vehicleID string) *OrderBuilder {
+	b.order.ForceVehicleID = VehicleID(vehicleID)
+	return b
+}
+
+// PickupAt starts the pickup step of the order at the given address.
+func (b *OrderBuilder) PickupAt(address string) *OrderBuilder {
+	b.order.Pickup = &OrderStep{Location: Location{Address: address}}
+	b.step = b.order.Pickup
+	return b
+}
+
+// PickupFromDepot starts the pickup step of the order at the given depot.
+func (b *OrderBuilder) PickupFromDepot(depotID string) *OrderBuilder {
+	b.order.Pickup = &OrderStep{DepotID: depotID}
+	b.step = b.order.Pickup
+	return b
+}
+
+// DeliverTo starts the delivery step of the order at the given address.
+func (b *OrderBuilder) DeliverTo(address string) *OrderBuilder {
+	b.order.Delivery = &OrderStep{Location: Location{Address: address}}
+	b.step = b.order.Delivery
+	return b
+}
+
+// DeliverToDepot starts the delivery step of the order at the given depot.
+func (b *OrderBuilder) DeliverToDepot(depotID string) *OrderBuilder {
+	b.order.Delivery = &OrderStep{DepotID: depotID}
+	b.step = b.order.Delivery
+	return b
+}
+
+// At sets the coordinates of the current step's location, which saves
+// WorkWave from geocoding its address.
+func (b *OrderBuilder) At(ll LatLng) *OrderBuilder {
+	if s := b.current("At"); s != nil {
+		s.Location.LatLng = &ll
+	}
+	return b
+}
+
+// Window adds a time window to the current step, given as the wall clock
+// times since midnight at which it starts and ends.
+func (b *OrderBuilder) Window(start, end time.Duration) *OrderBuilder {
+	if s := b.current("Window"); s != nil {
+		s.TimeWindows = append(s.TimeWindows, TimeWindow{
+			StartSec: SecOfDay(0).Add(start),
+			EndSec:   SecOfDay(0).Add(end),
+		})
+	}
+	return b
+}
+
+// WindowOn sets the time window of the current step on the given date,
+// replacing its usual time windows on that date.
+func (b *OrderBuilder) WindowOn(d Date, start, end time.Duration) *OrderBuilder {
+	if s := b.current("WindowOn"); s != nil {
+		if s.TimeWindowExceptions == nil {
+			s.TimeWindowExceptions = make(map[Date]TimeWindow)
+		}
+		s.TimeWindowExceptions[d] = TimeWindow{
+			StartSec: SecOfDay(0).Add(start),
+			EndSec:   SecOfDay(0).Add(end),
+		}
+	}
+	return b
+}
+
+// ServiceTime sets the time spent at the location of the current step.
+func (b *OrderBuilder) ServiceTime(d time.Duration) *OrderBuilder {
+	if s := b.current("ServiceTime"); s != nil {
+		s.ServiceTimeSec = int(d / time.Second)
+	}
+	return b
+}
+
+// Notes sets the notes of the current step.
+func (b *OrderBuilder) Notes(notes string) *OrderBuilder {
+	if s := b.current("Notes"); s != nil {
+		s.Notes = notes
+	}
+	return b
+}
+
+// TagsIn adds tags which a vehicle must have to serve the current step.
+func (b *OrderBuilder) TagsIn(tags ...string) *OrderBuilder {
+	if s := b.current("TagsIn"); s != nil {
+		s.TagsIn = append(s.TagsIn, tags...)
+	}
+	return b
+}
+
+// TagsOut adds tags which a vehicle must not have to serve the current step.
+func (b *OrderBuilder) TagsOut(tags ...string) *OrderBuilder {
+	if s := b.current("TagsOut"); s != nil {
+		s.TagsOut = append(s.TagsOut, tags...)
+	}
+	return b
+}
+
+// CustomField sets a custom field of the current step.
+func (b *OrderBuilder) CustomField(key, value string) *OrderBuilder {
+	if s := b.current("CustomField"); s != nil {
+		if s.CustomFields == nil {
+			s.CustomFields = make(map[string]string)
+		}
+		s.CustomFields[key] = value
+	}
+	return b
+}
+
+// Build returns the constructed Order after validating it. The error is
+// ValidationErrors, and also includes misuses of the builder such as setting
+// a time window before starting a step. The Order shares no memory with the
+// builder, which can go on to build variations of it.
+func (b *OrderBuilder) Build() (Order, error) {
+	o := copyOrder(b.order)
+	errs := append(ValidationErrors(nil), b.errs...)
+	if err := o.Validate(); err != nil {
+		verrs, ok := err.(ValidationErrors)
+		if !ok {
+			return o, err
+		}
+		errs = append(errs, verrs...)
+	}
+	if len(errs) > 0 {
+		return o, errs
+	}
+	return o, nil
+}
+
+// copyOrder returns a deep copy of the fields of o set by the builder.
+func copyOrder(o Order) Order {
+	o.Eligibility.OnDates = append([]Date(nil), o.Eligibility.OnDates...)
+	if o.Eligibility.ByDate != nil {
+		d := *o.Eligibility.ByDate
+		o.Eligibility.ByDate = &d
+	}
+	if o.Loads != nil {
+		o.Loads = Loads{}.Plus(o.Loads)
+	}
+	o.Pickup = copyStep(o.Pickup)
+	o.Delivery = copyStep(o.Delivery)
+	return o
+}
+
+func copyStep(s *OrderStep) *OrderStep {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if c.Location.LatLng != nil {
+		ll := *c.Location.LatLng
+		c.Location.LatLng = &ll
+	}
+	c.TimeWindows = append([]TimeWindow(nil), c.TimeWindows...)
+	if c.TimeWindowExceptions != nil {
+		c.TimeWindowExceptions = make(map[Date]TimeWindow, len(s.TimeWindowExceptions))
+		for d, tw := range s.TimeWindowExceptions {
+			c.TimeWindowExceptions[d] = tw
+		}
+	}
+	c.TagsIn = append([]string(nil), c.TagsIn...)
+	c.TagsOut = append([]string(nil), c.TagsOut...)
+	if c.CustomFields != nil {
+		c.CustomFields = make(map[string]string, len(s.CustomFields))
+		for k, v := range s.CustomFields {
+			c.CustomFields[k] = v
+		}
+	}
+	return &c
+}
+
+func (b *OrderBuilder) current(method string) *OrderStep {
+	if b.step == nil {
+		b.errs = append(b.errs, &ValidationError{
+			Index:   -1,
+			Message: fmt.Sprintf("%s requires a pickup or delivery step to be started first", method),
+		})
+	}
+	return b.step
+}
@@ -0,0 +1,128 @@
+package workwave
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	d1 := NewDate(2015, time.December, 4)
+	d2 := NewDate(2015, time.December, 5)
+
+	t.Run("delivery", func(t *testing.T) {
+		c := qt.New(t)
+		o, err := NewOrder("Order 1").
+			DeliverTo("3101-3199 Florida Ave, Jasper, AL 35501, USA").
+			At(LatLng{33817872, -87266893}).
+			Window(8*time.Hour+30*time.Minute, 10*time.Hour+30*time.Minute).
+			Window(12*time.Hour+30*time.Minute, 15*time.Hour+30*time.Minute).
+			WindowOn(d2, 9*time.Hour, 10*time.Hour).
+			ServiceTime(10*time.Minute).
+			Notes("Use the back door").
+			TagsIn("frozen").
+			CustomField("phone", "555-0100").
+			EligibleOn(d1, d2).
+			Priority(20).
+			Load("pallets", 2).
+			Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(o, qt.DeepEquals, Order{
+			Name:        "Order 1",
+			Eligibility: Eligibility{Type: EligibilityOn, OnDates: []Date{d1, d2}},
+			Priority:    20,
+			Loads:       Loads{"pallets": 2},
+			Delivery: &OrderStep{
+				Location: Location{
+					Address: "3101-3199 Florida Ave, Jasper, AL 35501, USA",
+					LatLng:  &LatLng{33817872, -87266893},
+				},
+				TimeWindows: []TimeWindow{
+					{StartSec: 30600, EndSec: 37800},
+					{StartSec: 45000, EndSec: 55800},
+				},
+				TimeWindowExceptions: map[Date]TimeWindow{d2: {StartSec: 32400, EndSec: 36000}},
+				Notes:                "Use the back door",
+				ServiceTimeSec:       600,
+				TagsIn:               []string{"frozen"},
+				CustomFields:         map[string]string{"phone": "555-0100"},
+			},
+		})
+	})
+
+	t.Run("pickup and delivery", func(t *testing.T) {
+		c := qt.New(t)
+		o, err := NewOrder("Order 2").
+			EligibleBy(d2).
+			PickupFromDepot("depot-1").
+			ServiceTime(5*time.Minute).
+			DeliverTo("710 7th Ave, Jasper, AL 35504, USA").
+			Window(9*time.Hour, 11*time.Hour).
+			ForceVehicle("vehicle-1").
+			Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(*o.Eligibility.ByDate, qt.Equals, d2)
+		c.Assert(o.Pickup, qt.DeepEquals, &OrderStep{DepotID: "depot-1", ServiceTimeSec: 300})
+		c.Assert(o.Delivery.TimeWindows, qt.DeepEquals, []TimeWindow{{StartSec: 32400, EndSec: 39600}})
+		c.Assert(o.ForceVehicleID, qt.Equals, VehicleID("vehicle-1"))
+	})
+
+	t.Run("service", func(t *testing.T) {
+		c := qt.New(t)
+		o, err := NewOrder("Boiler check").
+			EligibleAny().
+			Service().
+			PickupAt("701-799 Birmingham Ave, Jasper, AL 35501, USA").
+			TagsOut("heavy").
+			Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(o.IsService, qt.Equals, true)
+		c.Assert(o.Delivery, qt.IsNil)
+		c.Assert(o.Pickup.TagsOut, qt.DeepEquals, []string{"heavy"})
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := NewOrder("Order 3").
+			Window(9*time.Hour, 8*time.Hour).
+			EligibleAny().
+			DeliverToDepot("depot-1").
+			Window(11*time.Hour, 10*time.Hour).
+			Build()
+		c.Assert(err, qt.ErrorMatches, "Window requires a pickup or delivery step to be started first; "+
+			"delivery.timeWindows\\[0\\]: must end after it starts at 11:00")
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		c := qt.New(t)
+		bld := NewOrder("Order 4").
+			EligibleOn(d1).
+			Load("pallets", 1).
+			DeliverTo("710 7th Ave, Jasper, AL 35504, USA").
+			At(LatLng{33817872, -87266893}).
+			Window(9*time.Hour, 11*time.Hour).
+			WindowOn(d1, 9*time.Hour, 10*time.Hour).
+			Notes("first").
+			TagsIn("frozen").
+			CustomField("phone", "555-0100")
+		o1, err := bld.Build()
+		c.Assert(err, qt.IsNil)
+		want := copyOrder(o1)
+
+		o2, err := bld.EligibleOn(d2).
+			Load("pallets", 2).
+			At(LatLng{1, 2}).
+			Window(12*time.Hour, 13*time.Hour).
+			WindowOn(d1, 10*time.Hour, 11*time.Hour).
+			Notes("changed").
+			TagsIn("dry").
+			CustomField("phone", "555-0101").
+			Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(o1, qt.DeepEquals, want)
+		c.Assert(o1.Delivery.Notes, qt.Equals, "first")
+		c.Assert(o2.Delivery.Notes, qt.Equals, "changed")
+		c.Assert(o2.Delivery.TagsIn, qt.DeepEquals, []string{"frozen", "dry"})
+	})
+}
@@ -0,0 +1,412 @@
+package workwave
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBulkChunkSize   = 500
+	defaultBulkConcurrency = 4
+	defaultCompletionTTL   = 10 * time.Minute
+)
+
+// RequestAwaiter waits for an asynchronous WorkWave request, such as adding
+// orders, to complete. It returns an error if the request failed.
+type RequestAwaiter interface {
+	Await(ctx context.Context, requestID string) error
+}
+
+// RequestTracker is a RequestAwaiter for requests whose completion is
+// reported through WorkWave callbacks: the callback handler calls Complete
+// with the requestId of each completed request.
+type RequestTracker struct {
+	// TTL is how long completions of requests which are not awaited yet are
+	// kept, as a callback can arrive before its request is awaited. Defaults
+	// to 10 minutes.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	now     func() time.Time
+	waiters map[string][]chan error
+	done    map[string]completion
+	queue   []queuedCompletion // done, oldest first
+}
+
+type completion struct {
+	err error
+	at  time.Time
+}
+
+type queuedCompletion struct {
+	requestID string
+	at        time.Time
+}
+
+// NewRequestTracker creates an empty RequestTracker.
+func NewRequestTracker() *RequestTracker {
+	return &RequestTracker{
+		now:     time.Now,
+		waiters: make(map[string][]chan error),
+		done:    make(map[string]completion),
+	}
+}
+
+// Complete records that the request with the given ID has completed, with a
+// non-nil err if it failed, and releases anyone awaiting it. Completions for
+// requests which are not yet awaited are kept until they are, or until they
+// are older than the TTL.
+func (t *RequestTracker) Complete(requestID string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.expire(now)
+	waiters := t.waiters[requestID]
+	if len(waiters) == 0 {
+		t.done[requestID] = completion{err: err, at: now}
+		t.queue = append(t.queue, queuedCompletion{requestID: requestID, at: now})
+		return
+	}
+	delete(t.waiters, requestID)
+	for _, w := range waiters {
+		w <- err
+	}
+}
+
+// Await blocks until the request with the given ID completes or ctx is done.
+func (t *RequestTracker) Await(ctx context.Context, requestID string) error {
+	t.mu.Lock()
+	t.expire(t.now())
+	if c, ok := t.done[requestID]; ok {
+		delete(t.done, requestID)
+		t.mu.Unlock()
+		return c.err
+	}
+	w := make(chan error, 1)
+	t.waiters[requestID] = append(t.waiters[requestID], w)
+	t.mu.Unlock()
+
+	select {
+	case err := <-w:
+		return err
+	case <-ctx.Done():
+		t.mu.Lock()
+		ws := t.waiters[requestID]
+		for i := range ws {
+			if ws[i] == w {
+				t.waiters[requestID] = append(ws[:i], ws[i+1:]...)
+				break
+			}
+		}
+		if len(t.waiters[requestID]) == 0 {
+			delete(t.waiters, requestID)
+		}
+		t.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// expire drops the completions which are older than the TTL at now.
+func (t *RequestTracker) expire(now time.Time) {
+	ttl := t.TTL
+	if ttl <= 0 {
+		ttl = defaultCompletionTTL
+	}
+	i := 0
+	for ; i < len(t.queue) && now.Sub(t.queue[i].at) > ttl; i++ {
+		q := t.queue[i]
+		if c, ok := t.done[q.requestID]; ok && c.at.Equal(q.at) {
+			delete(t.done, q.requestID)
+		}
+	}
+	t.queue = t.queue[i:]
+}
+
+// BulkAddInput is used to populate a call to BulkAddOrders.
+type BulkAddInput struct {
+	TerritoryID       string
+	Orders            []Order
+	Strict            bool
+	AcceptBadGeocodes bool
+	// ChunkSize is the maximum number of orders submitted per request.
+	// Defaults to 500.
+	ChunkSize int
+	// Concurrency is the maximum number of requests in flight at once.
+	// Defaults to 4.
+	Concurrency int
+	// Awaiter is optional. If set, each chunk's request is awaited after
+	// being submitted, and its outcome included in the report.
+	Awaiter RequestAwaiter
+	// Registry is optional. If set, the orders are validated against it, and
+	// none are submitted unless all are valid.
+	Registry *Registry
+}
+
+// BulkOrderStatus is the outcome of an order submitted by BulkAddOrders.
+type BulkOrderStatus int
+
+// Bulk order statuses.
+const (
+	// BulkPending orders were not submitted, because the context was done
+	// before their chunk was started, or because some orders were not valid.
+	BulkPending BulkOrderStatus = iota
+	// BulkSubmitted orders were accepted by WorkWave and are being added
+	// asynchronously. Orders remain submitted when no Awaiter is given, or
+	// when the context is done before their request completes.
+	BulkSubmitted
+	// BulkCompleted orders were added by a request which completed.
+	BulkCompleted
+	// BulkFailed orders were either rejected when submitted, or added by a
+	// request which failed.
+	BulkFailed
+)
+
+func (s BulkOrderStatus) String() string {
+	switch s {
+	case BulkPending:
+		return "pending"
+	case BulkSubmitted:
+		return "submitted"
+	case BulkCompleted:
+		return "completed"
+	case BulkFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// BulkChunk is a slice of the input orders submitted in a single request.
+type BulkChunk struct {
+	// Start and End are the bounds of the chunk within the input orders,
+	// ie Orders[Start:End].
+	Start     int
+	End       int
+	RequestID string
+	Status    BulkOrderStatus
+	Err       error
+}
+
+// BulkOrderResult is the outcome of a single input order.
+type BulkOrderResult struct {
+	Chunk     int // index of the order's chunk in BulkAddReport.Chunks
+	RequestID string
+	Status    BulkOrderStatus
+	Err       error
+}
+
+// BulkAddReport is the outcome of BulkAddOrders. Orders has one entry per
+// input order, at the same index.
+type BulkAddReport struct {
+	Chunks []BulkChunk
+	Orders []BulkOrderResult
+}
+
+// BulkAddOrders adds a large number of orders by splitting them into chunks
+// of at most ChunkSize orders, each submitted with OrdersService.Add, with at
+// most Concurrency requests in flight. The returned report maps every input
+// order to the outcome of its chunk. An error is returned alongside the report
+// if any chunk was not submitted, failed, or could not be awaited.
+//
+// If the orders are not valid against the Registry, nothing is submitted and
+// the ValidationErrors are returned alongside a report of pending orders,
+// where each invalid order has its own errors.
+func BulkAddOrders(ctx context.Context, svc OrdersService, i BulkAddInput) (*BulkAddReport, error) {
+	size := i.ChunkSize
+	if size <= 0 {
+		size = defaultBulkChunkSize
+	}
+	concurrency := i.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	report := &BulkAddReport{Orders: make([]BulkOrderResult, len(i.Orders))}
+	for start := 0; start < len(i.Orders); start += size {
+		end := start + size
+		if end > len(i.Orders) {
+			end = len(i.Orders)
+		}
+		report.Chunks = append(report.Chunks, BulkChunk{Start: start, End: end})
+	}
+
+	if i.Registry != nil {
+		if err := i.Registry.ValidateOrders(i.Orders); err != nil {
+			var byIndex map[int]ValidationErrors
+			if verrs, ok := err.(ValidationErrors); ok {
+				byIndex = verrs.ByIndex()
+			}
+			for n, chunk := range report.Chunks {
+				for o := chunk.Start; o < chunk.End; o++ {
+					report.Orders[o] = BulkOrderResult{Chunk: n, Status: BulkPending}
+					if errs, ok := byIndex[o]; ok {
+						report.Orders[o].Err = errs
+					}
+				}
+			}
+			return report, err
+		}
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for n := range report.Chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			report.Chunks[n].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(chunk *BulkChunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			submitChunk(ctx, svc, i, chunk)
+		}(&report.Chunks[n])
+	}
+	wg.Wait()
+
+	failed := 0
+	for n, chunk := range report.Chunks {
+		if chunk.Err != nil {
+			failed++
+		}
+		for o := chunk.Start; o < chunk.End; o++ {
+			report.Orders[o] = BulkOrderResult{
+				Chunk:     n,
+				RequestID: chunk.RequestID,
+				Status:    chunk.Status,
+				Err:       chunk.Err,
+			}
+		}
+	}
+	if failed > 0 {
+		return report, errors.Errorf("%d of %d order chunks failed", failed, len(report.Chunks))
+	}
+	return report, nil
+}
+
+func submitChunk(ctx context.Context, svc OrdersService, i BulkAddInput, chunk *BulkChunk) {
+	id, err := svc.Add(ctx, OrdersAddInput{
+		TerritoryID:       i.TerritoryID,
+		Orders:            i.Orders[chunk.Start:chunk.End],
+		Strict:            i.Strict,
+		AcceptBadGeocodes: i.AcceptBadGeocodes,
+	})
+	if err != nil {
+		chunk.Status = BulkFailed
+		chunk.Err = errors.Wrap(err, "failed to submit orders")
+		return
+	}
+	chunk.RequestID = id
+	chunk.Status, chunk.Err = await(ctx, i.Awaiter, id)
+}
+
+// await returns the status of a submitted request, awaiting it if there is an
+// awaiter.
+func await(ctx context.Context, awaiter RequestAwaiter, id string) (BulkOrderStatus, error) {
+	if awaiter == nil {
+		return BulkSubmitted, nil
+	}
+	if err := awaiter.Await(ctx, id); err != nil {
+		if ctx.Err() != nil {
+			// The outcome of the request is unknown.
+			return BulkSubmitted, errors.Wrapf(err, "failed to await request %s", id)
+		}
+		return BulkFailed, errors.Wrapf(err, "request %s failed", id)
+	}
+	return BulkCompleted, nil
+}
+
+// PinOrdersInput is used to populate a call to PinOrders.
+type PinOrdersInput struct {
+	TerritoryID string
+	OrderIDs    []string
+	// VehicleID is the vehicle the orders are forced onto. It is ignored by
+	// UnpinOrders.
+	VehicleID VehicleID
+	// Concurrency is the maximum number of requests in flight at once.
+	// Defaults to 4.
+	Concurrency int
+	// Awaiter is optional. If set, each order's request is awaited after
+	// being submitted, and its outcome included in the results.
+	Awaiter RequestAwaiter
+}
+
+// PinResult is the outcome of pinning or unpinning a single order.
+type PinResult struct {
+	OrderID   string
+	RequestID string
+	Status    BulkOrderStatus
+	Err       error
+}
+
+// PinOrders forces each of the given orders onto a vehicle, by updating their
+// forceVehicleId with OrdersService.Update. The results have one entry per
+// order ID, at the same index. An error is returned alongside the results if
+// any order was not submitted, failed, or could not be awaited.
+func PinOrders(ctx context.Context, svc OrdersUpdater, i PinOrdersInput) ([]PinResult, error) {
+	concurrency := i.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+
+	results := make([]PinResult, len(i.OrderIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for n, id := range i.OrderIDs {
+		results[n].OrderID = id
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[n].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(r *PinResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			pinOrder(ctx, svc, i, r)
+		}(&results[n])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, errors.Errorf("%d of %d orders failed", failed, len(results))
+	}
+	return results, nil
+}
+
+// UnpinOrders releases each of the given orders from the vehicle they are
+// forced onto, like PinOrders.
+func UnpinOrders(ctx context.Context, svc OrdersUpdater, i PinOrdersInput) ([]PinResult, error) {
+	i.VehicleID = ""
+	return PinOrders(ctx, svc, i)
+}
+
+func pinOrder(ctx context.Context, svc OrdersUpdater, i PinOrdersInput, r *PinResult) {
+	id, err := svc.Update(ctx, OrdersUpdateInput{
+		TerritoryID: i.TerritoryID,
+		OrderID:     r.OrderID,
+		Order:       Order{ForceVehicleID: i.VehicleID},
+		Fields:      []string{"forceVehicleId"},
+	})
+	if err != nil {
+		r.Status = BulkFailed
+		r.Err = errors.Wrapf(err, "failed to update order %s", r.OrderID)
+		return
+	}
+	r.RequestID = id
+	r.Status, r.Err = await(ctx, i.Awaiter, id)
+}
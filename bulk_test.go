@@ -0,0 +1,219 @@
+package workwave
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/pkg/errors"
+)
+
+type fakeOrders struct {
+	OrdersService
+
+	mu       sync.Mutex
+	calls    [][]Order
+	inFlight int
+	maxInUse int
+	fail     map[string]bool // fail chunks whose first order has this name, and updates of this order ID
+	updates  []OrdersUpdateInput
+}
+
+func (f *fakeOrders) Add(ctx context.Context, i OrdersAddInput) (string, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, i.Orders)
+	f.inFlight++
+	if f.inFlight > f.maxInUse {
+		f.maxInUse = f.inFlight
+	}
+	f.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+
+	if f.fail[i.Orders[0].Name] {
+		return "", errors.New("HTTP 400 error")
+	}
+	return "req-" + i.Orders[0].Name, nil
+}
+
+func (f *fakeOrders) Update(ctx context.Context, i OrdersUpdateInput) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, i)
+	if f.fail[i.OrderID] {
+		return "", errors.New("HTTP 400 error")
+	}
+	return "req-" + i.OrderID, nil
+}
+
+func bulkOrders(n int) []Order {
+	orders := make([]Order, n)
+	for i := range orders {
+		orders[i] = Order{Name: fmt.Sprint(i)}
+	}
+	return orders
+}
+
+func TestBulkAddOrders(t *testing.T) {
+	t.Run("chunks and concurrency", func(t *testing.T) {
+		c := qt.New(t)
+		svc := &fakeOrders{}
+		report, err := BulkAddOrders(ctx, svc, BulkAddInput{
+			TerritoryID: "territory",
+			Orders:      bulkOrders(25),
+			ChunkSize:   10,
+			Concurrency: 2,
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(svc.calls), qt.Equals, 3)
+		c.Assert(svc.maxInUse <= 2, qt.Equals, true)
+
+		c.Assert(len(report.Chunks), qt.Equals, 3)
+		c.Assert(report.Chunks[2], qt.DeepEquals, BulkChunk{
+			Start:     20,
+			End:       25,
+			RequestID: "req-20",
+			Status:    BulkSubmitted,
+		})
+		c.Assert(len(report.Orders), qt.Equals, 25)
+		c.Assert(report.Orders[14], qt.DeepEquals, BulkOrderResult{
+			Chunk:     1,
+			RequestID: "req-10",
+			Status:    BulkSubmitted,
+		})
+	})
+
+	t.Run("failed chunk", func(t *testing.T) {
+		c := qt.New(t)
+		svc := &fakeOrders{fail: map[string]bool{"10": true}}
+		report, err := BulkAddOrders(ctx, svc, BulkAddInput{
+			Orders:    bulkOrders(25),
+			ChunkSize: 10,
+		})
+		c.Assert(err, qt.ErrorMatches, "1 of 3 order chunks failed")
+		c.Assert(report.Orders[9].Status, qt.Equals, BulkSubmitted)
+		c.Assert(report.Orders[10].Status, qt.Equals, BulkFailed)
+		c.Assert(report.Orders[10].Err, qt.ErrorMatches, "failed to submit orders: HTTP 400 error")
+		c.Assert(report.Orders[20].Status, qt.Equals, BulkSubmitted)
+	})
+
+	t.Run("awaited", func(t *testing.T) {
+		c := qt.New(t)
+		tracker := NewRequestTracker()
+		// Complete one request before it is awaited and the others after.
+		tracker.Complete("req-0", nil)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			tracker.Complete("req-2", errors.New("invalid address"))
+			tracker.Complete("req-4", nil)
+		}()
+
+		report, err := BulkAddOrders(ctx, &fakeOrders{}, BulkAddInput{
+			Orders:    bulkOrders(5),
+			ChunkSize: 2,
+			Awaiter:   tracker,
+		})
+		c.Assert(err, qt.ErrorMatches, "1 of 3 order chunks failed")
+		c.Assert(report.Orders[1].Status, qt.Equals, BulkCompleted)
+		c.Assert(report.Orders[2].Status, qt.Equals, BulkFailed)
+		c.Assert(report.Orders[3].Err, qt.ErrorMatches, "request req-2 failed: invalid address")
+		c.Assert(report.Orders[4].Status, qt.Equals, BulkCompleted)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		c := qt.New(t)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		report, err := BulkAddOrders(cctx, &fakeOrders{}, BulkAddInput{
+			Orders:      bulkOrders(3),
+			ChunkSize:   1,
+			Concurrency: 1,
+			Awaiter:     NewRequestTracker(),
+		})
+		c.Assert(err, qt.Not(qt.IsNil))
+		for _, o := range report.Orders {
+			c.Assert(o.Err, qt.Not(qt.IsNil))
+			c.Assert(o.Status, qt.Not(qt.Equals), BulkCompleted)
+		}
+	})
+}
+
+func TestRequestTrackerAwaitTimeout(t *testing.T) {
+	c := qt.New(t)
+	tracker := NewRequestTracker()
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	err := tracker.Await(tctx, "req-1")
+	c.Assert(err, qt.Equals, context.DeadlineExceeded)
+	c.Assert(len(tracker.waiters), qt.Equals, 0)
+
+	// A late completion is kept for a later Await.
+	tracker.Complete("req-1", nil)
+	c.Assert(tracker.Await(ctx, "req-1"), qt.IsNil)
+}
+
+func TestRequestTrackerExpire(t *testing.T) {
+	c := qt.New(t)
+	now := time.Date(2019, 10, 19, 9, 0, 0, 0, time.UTC)
+	tracker := NewRequestTracker()
+	tracker.TTL = time.Minute
+	tracker.now = func() time.Time { return now }
+
+	tracker.Complete("req-1", nil)
+	tracker.Complete("req-2", errors.New("failed"))
+	c.Assert(tracker.Await(ctx, "req-1"), qt.IsNil)
+
+	// Completions which are never awaited are dropped after the TTL.
+	now = now.Add(2 * time.Minute)
+	tracker.Complete("req-3", nil)
+	c.Assert(tracker.done, qt.HasLen, 1)
+	c.Assert(tracker.queue, qt.HasLen, 1)
+	c.Assert(tracker.Await(ctx, "req-3"), qt.IsNil)
+}
+
+func TestPinOrders(t *testing.T) {
+	c := qt.New(t)
+	svc := &fakeOrders{fail: map[string]bool{"o2": true}}
+	tracker := NewRequestTracker()
+	tracker.Complete("req-o1", nil)
+	tracker.Complete("req-o3", errors.New("unknown vehicle"))
+
+	results, err := PinOrders(ctx, svc, PinOrdersInput{
+		TerritoryID: "territory",
+		OrderIDs:    []string{"o1", "o2", "o3"},
+		VehicleID:   "v1",
+		Awaiter:     tracker,
+	})
+	c.Assert(err, qt.ErrorMatches, "2 of 3 orders failed")
+	c.Assert(results, qt.HasLen, 3)
+	c.Assert(results[0], qt.DeepEquals, PinResult{OrderID: "o1", RequestID: "req-o1", Status: BulkCompleted})
+	c.Assert(results[1].Status, qt.Equals, BulkFailed)
+	c.Assert(results[1].Err, qt.ErrorMatches, "failed to update order o2: HTTP 400 error")
+	c.Assert(results[2].Status, qt.Equals, BulkFailed)
+	c.Assert(results[2].Err, qt.ErrorMatches, "request req-o3 failed: unknown vehicle")
+
+	c.Assert(svc.updates, qt.HasLen, 3)
+	for _, u := range svc.updates {
+		c.Check(u.TerritoryID, qt.Equals, "territory")
+		c.Check(u.Order.ForceVehicleID, qt.Equals, VehicleID("v1"))
+		c.Check(u.Fields, qt.DeepEquals, []string{"forceVehicleId"})
+	}
+
+	svc = &fakeOrders{}
+	results, err = UnpinOrders(ctx, svc, PinOrdersInput{
+		TerritoryID: "territory",
+		OrderIDs:    []string{"o1"},
+		VehicleID:   "v1",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(results, qt.DeepEquals, []PinResult{{OrderID: "o1", RequestID: "req-o1", Status: BulkSubmitted}})
+	c.Assert(svc.updates[0].Order.ForceVehicleID, qt.Equals, VehicleID(""))
+}
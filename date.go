@@ -0,0 +1,208 @@
+package workwave
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "20060102"
+
+// Date is a calendar date without a time of day or location, as used by the
+// WorkWave API in the format yyyyMMdd. The zero Date is encoded as an empty
+// string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the Date for the given year, month and day, normalizing
+// out of range values the same way as time.Date.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the Date on which t falls, in t's location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a date in the format yyyyMMdd.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, errors.Wrapf(err, "invalid date %q", s)
+	}
+	return DateOf(t), nil
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns d in the format yyyyMMdd, or an empty string for the zero
+// Date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the time of midnight at the start of d in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days after d. n may be negative.
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// Sub returns the number of days from o to d.
+func (d Date) Sub(o Date) int {
+	return int(d.In(time.UTC).Sub(o.In(time.UTC)) / (24 * time.Hour))
+}
+
+// Before reports whether d is before o.
+func (d Date) Before(o Date) bool {
+	return d.Sub(o) < 0
+}
+
+// After reports whether d is after o.
+func (d Date) After(o Date) bool {
+	return d.Sub(o) > 0
+}
+
+// Weekday returns the day of the week of d.
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Date) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*d = Date{}
+		return nil
+	}
+	v, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// SecOfDay is a time of day expressed as seconds since midnight, as used by
+// WorkWave time windows and route steps. Values of a day or more are used for
+// times past midnight on routes spanning two days, and -1 is used by the API
+// for times that are not set.
+//
+// SecOfDay is a wall clock time: on days with a daylight saving time
+// transition, 9:00 is always 32400 regardless of the hours gained or lost
+// since midnight.
+type SecOfDay int
+
+// NewSecOfDay returns the SecOfDay for the given wall clock time.
+func NewSecOfDay(hour, min, sec int) SecOfDay {
+	return SecOfDay(hour*3600 + min*60 + sec)
+}
+
+// SecOfDayOf returns the wall clock time of t, in t's location, as seconds
+// since midnight on d. Times on days after d are offset by a day per day.
+func SecOfDayOf(d Date, t time.Time) SecOfDay {
+	days := DateOf(t).Sub(d)
+	return SecOfDay(days*86400 + t.Hour()*3600 + t.Minute()*60 + t.Second())
+}
+
+// ParseSecOfDay parses a wall clock time in the format HH:MM or HH:MM:SS.
+// Hours may exceed 23 for times past midnight.
+func ParseSecOfDay(s string) (SecOfDay, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, errors.Errorf("invalid time of day %q", s)
+	}
+	var v [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, errors.Errorf("invalid time of day %q", s)
+		}
+		v[i] = n
+	}
+	return NewSecOfDay(v[0], v[1], v[2]), nil
+}
+
+// IsSet reports whether s holds a time, as the API uses negative values for
+// times that are not set.
+func (s SecOfDay) IsSet() bool {
+	return s >= 0
+}
+
+// Clock returns the hour, minute and second of s. The hour may exceed 23.
+func (s SecOfDay) Clock() (hour, min, sec int) {
+	v := int(s)
+	return v / 3600, v % 3600 / 60, v % 60
+}
+
+// Duration returns s as the duration since midnight on the wall clock.
+func (s SecOfDay) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// Add returns s shifted by d, truncated to the second.
+func (s SecOfDay) Add(d time.Duration) SecOfDay {
+	return s + SecOfDay(d/time.Second)
+}
+
+// Sub returns the wall clock duration from o to s.
+func (s SecOfDay) Sub(o SecOfDay) time.Duration {
+	return time.Duration(s-o) * time.Second
+}
+
+// String returns s in the format HH:MM, or HH:MM:SS when s is not a whole
+// minute. Unset times are returned as --:--.
+func (s SecOfDay) String() string {
+	if !s.IsSet() {
+		return "--:--"
+	}
+	h, m, sec := s.Clock()
+	if sec != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+// Time returns the instant at which the wall clock in loc shows s on d.
+// Wall clock times skipped by a daylight saving time transition are moved
+// forward by the length of the transition, so 2:30 becomes 3:30 when clocks
+// go forward from 2:00 to 3:00. Wall clock times repeated by a transition
+// resolve to their first occurrence.
+func (s SecOfDay) Time(d Date, loc *time.Location) time.Time {
+	wall := time.Date(d.Year, d.Month, d.Day, 0, 0, int(s), 0, time.UTC).Unix()
+	t := time.Date(d.Year, d.Month, d.Day, 0, 0, int(s), 0, loc)
+	_, before := t.Add(-12 * time.Hour).Zone()
+	_, after := t.Add(12 * time.Hour).Zone()
+
+	// Trying the offset in effect before a transition first resolves repeated
+	// wall clock times to their first occurrence. Neither offset shows a
+	// skipped wall clock time, which the offset before moves forward.
+	for _, offset := range []int{before, after} {
+		u := time.Unix(wall-int64(offset), 0).In(loc)
+		if _, o := u.Zone(); o == offset {
+			return u
+		}
+	}
+	return time.Unix(wall-int64(before), 0).In(loc)
+}
@@ -0,0 +1,288 @@
+package workwave
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func loadLocation(c *qt.C, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		c.Skip("timezone data not available: ", err)
+	}
+	return loc
+}
+
+func TestParseDate(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "20191019", want: Date{Year: 2019, Month: time.October, Day: 19}},
+		{in: "20200229", want: Date{Year: 2020, Month: time.February, Day: 29}},
+		{in: "20190229", wantErr: true},
+		{in: "2019-10-19", wantErr: true},
+		{in: "", wantErr: true},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			c := qt.New(t)
+			d, err := ParseDate(tt.in)
+			if tt.wantErr {
+				c.Assert(err, qt.ErrorMatches, "invalid date.*")
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(d, qt.Equals, tt.want)
+			c.Assert(d.String(), qt.Equals, tt.in)
+		})
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	c := qt.New(t)
+	d := NewDate(2019, time.December, 31)
+
+	c.Assert(d.AddDays(1), qt.Equals, NewDate(2020, time.January, 1))
+	c.Assert(d.AddDays(-365), qt.Equals, NewDate(2018, time.December, 31))
+	c.Assert(NewDate(2020, time.March, 1).Sub(NewDate(2020, time.February, 1)), qt.Equals, 29)
+	c.Assert(d.Before(d.AddDays(1)), qt.Equals, true)
+	c.Assert(d.After(d.AddDays(1)), qt.Equals, false)
+	c.Assert(d.Weekday(), qt.Equals, time.Tuesday)
+	c.Assert(NewDate(2019, time.February, 30), qt.Equals, NewDate(2019, time.March, 2))
+	c.Assert(Date{}.IsZero(), qt.Equals, true)
+	c.Assert(Date{}.String(), qt.Equals, "")
+}
+
+func TestDateAcrossDST(t *testing.T) {
+	c := qt.New(t)
+	loc := loadLocation(c, "America/New_York")
+
+	// The 23 hour day of the spring transition still counts as one day.
+	spring := NewDate(2019, time.March, 10)
+	c.Assert(spring.AddDays(1).Sub(spring), qt.Equals, 1)
+	c.Assert(spring.AddDays(1).In(loc).Sub(spring.In(loc)), qt.Equals, 23*time.Hour)
+
+	// A late evening time in loc is still on the same date.
+	tm := time.Date(2019, time.November, 3, 23, 30, 0, 0, loc)
+	c.Assert(DateOf(tm), qt.Equals, NewDate(2019, time.November, 3))
+	c.Assert(DateOf(tm.UTC()), qt.Equals, NewDate(2019, time.November, 4))
+}
+
+func TestDateJSON(t *testing.T) {
+	c := qt.New(t)
+
+	var e Eligibility
+	err := json.Unmarshal([]byte(`{"type": "on", "onDates": ["20151204", "20151205"]}`), &e)
+	c.Assert(err, qt.IsNil)
+	c.Assert(e.OnDates, qt.DeepEquals, []Date{NewDate(2015, time.December, 4), NewDate(2015, time.December, 5)})
+	c.Assert(e.ByDate, qt.IsNil)
+
+	b, err := json.Marshal(e)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"type":"on","onDates":["20151204","20151205"]}`)
+
+	err = json.Unmarshal([]byte(`{"onDates": ["2015-12-04"]}`), &e)
+	c.Assert(err, qt.ErrorMatches, `invalid date "2015-12-04".*`)
+
+	var s OrderStep
+	err = json.Unmarshal([]byte(`{"timeWindowExceptions": {"20151224": {"startSec": 28800, "endSec": 43200}}}`), &s)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.TimeWindowExceptions, qt.DeepEquals, map[Date]TimeWindow{
+		NewDate(2015, time.December, 24): {StartSec: 28800, EndSec: 43200},
+	})
+	b, err = json.Marshal(s.TimeWindowExceptions)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"20151224":{"startSec":28800,"endSec":43200}}`)
+
+	// Zero route dates are omitted.
+	b, err = json.Marshal(Route{ID: "r"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"id":"r"}`)
+	r := Route{ID: "r", Date: NewDate(2015, time.December, 4), Extra: map[string]json.RawMessage{"date": json.RawMessage(`"x"`)}}
+	b, err = json.Marshal(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"id":"r","date":"20151204"}`)
+	var got Route
+	c.Assert(json.Unmarshal(b, &got), qt.IsNil)
+	c.Assert(got.Date, qt.Equals, r.Date)
+}
+
+func TestParseSecOfDay(t *testing.T) {
+	for _, tt := range []struct {
+		in      string
+		want    SecOfDay
+		wantErr bool
+	}{
+		{in: "09:30", want: 34200},
+		{in: "9:30:15", want: 34215},
+		{in: "00:00", want: 0},
+		{in: "25:00", want: 90000},
+		{in: "09:60", wantErr: true},
+		{in: "09", wantErr: true},
+		{in: "-1:00", wantErr: true},
+		{in: "ab:cd", wantErr: true},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			c := qt.New(t)
+			s, err := ParseSecOfDay(tt.in)
+			if tt.wantErr {
+				c.Assert(err, qt.ErrorMatches, "invalid time of day.*")
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(s, qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestSecOfDay(t *testing.T) {
+	c := qt.New(t)
+	s := NewSecOfDay(9, 30, 0)
+
+	c.Assert(s, qt.Equals, SecOfDay(34200))
+	c.Assert(s.String(), qt.Equals, "09:30")
+	c.Assert(s.Add(90*time.Second).String(), qt.Equals, "09:31:30")
+	c.Assert(s.Add(15*time.Hour).String(), qt.Equals, "24:30")
+	c.Assert(s.Sub(NewSecOfDay(8, 0, 0)), qt.Equals, 90*time.Minute)
+	c.Assert(s.Duration(), qt.Equals, 9*time.Hour+30*time.Minute)
+	c.Assert(SecOfDay(-1).IsSet(), qt.Equals, false)
+	c.Assert(SecOfDay(-1).String(), qt.Equals, "--:--")
+
+	h, m, sec := SecOfDay(34215).Clock()
+	c.Assert([]int{h, m, sec}, qt.DeepEquals, []int{9, 30, 15})
+
+	b, err := json.Marshal(TimeWindow{StartSec: s, EndSec: s.Add(time.Hour)})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"startSec":34200,"endSec":37800}`)
+	var tw TimeWindow
+	c.Assert(json.Unmarshal(b, &tw), qt.IsNil)
+	c.Assert(tw, qt.Equals, TimeWindow{StartSec: 34200, EndSec: 37800})
+}
+
+func TestSecOfDayTimeDST(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		zone     string
+		date     Date
+		sec      SecOfDay
+		want     string
+		fromZero time.Duration
+	}{
+		{
+			name:     "new york, before spring transition",
+			zone:     "America/New_York",
+			date:     NewDate(2019, time.March, 10),
+			sec:      NewSecOfDay(1, 30, 0),
+			want:     "2019-03-10T01:30:00-05:00",
+			fromZero: 90 * time.Minute,
+		},
+		{
+			name:     "new york, after spring transition",
+			zone:     "America/New_York",
+			date:     NewDate(2019, time.March, 10),
+			sec:      NewSecOfDay(9, 0, 0),
+			want:     "2019-03-10T09:00:00-04:00",
+			fromZero: 8 * time.Hour,
+		},
+		{
+			name:     "new york, skipped by spring transition",
+			zone:     "America/New_York",
+			date:     NewDate(2019, time.March, 10),
+			sec:      NewSecOfDay(2, 30, 0),
+			want:     "2019-03-10T03:30:00-04:00",
+			fromZero: 150 * time.Minute,
+		},
+		{
+			name:     "new york, repeated by fall transition",
+			zone:     "America/New_York",
+			date:     NewDate(2019, time.November, 3),
+			sec:      NewSecOfDay(1, 30, 0),
+			want:     "2019-11-03T01:30:00-04:00",
+			fromZero: 90 * time.Minute,
+		},
+		{
+			name:     "new york, after fall transition",
+			zone:     "America/New_York",
+			date:     NewDate(2019, time.November, 3),
+			sec:      NewSecOfDay(9, 0, 0),
+			want:     "2019-11-03T09:00:00-05:00",
+			fromZero: 10 * time.Hour,
+		},
+		{
+			name:     "rome, after spring transition",
+			zone:     "Europe/Rome",
+			date:     NewDate(2019, time.March, 31),
+			sec:      NewSecOfDay(8, 0, 0),
+			want:     "2019-03-31T08:00:00+02:00",
+			fromZero: 7 * time.Hour,
+		},
+		{
+			name:     "rome, past midnight repeated by fall transition",
+			zone:     "Europe/Rome",
+			date:     NewDate(2019, time.October, 26),
+			sec:      NewSecOfDay(26, 0, 0),
+			want:     "2019-10-27T02:00:00+02:00",
+			fromZero: 26 * time.Hour,
+		},
+		{
+			name:     "rome, repeated by fall transition",
+			zone:     "Europe/Rome",
+			date:     NewDate(2019, time.October, 27),
+			sec:      NewSecOfDay(2, 30, 0),
+			want:     "2019-10-27T02:30:00+02:00",
+			fromZero: 150 * time.Minute,
+		},
+		{
+			name:     "rome, after fall transition",
+			zone:     "Europe/Rome",
+			date:     NewDate(2019, time.October, 27),
+			sec:      NewSecOfDay(3, 0, 0),
+			want:     "2019-10-27T03:00:00+01:00",
+			fromZero: 4 * time.Hour,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			loc := loadLocation(c, tt.zone)
+
+			got := tt.sec.Time(tt.date, loc)
+			c.Assert(got.Format(time.RFC3339), qt.Equals, tt.want)
+			c.Assert(got.Sub(tt.date.In(loc)), qt.Equals, tt.fromZero)
+
+			// Times that exist on the wall clock of the date round trip.
+			if got.Format("15:04") == tt.sec.String() {
+				c.Assert(SecOfDayOf(tt.date, got), qt.Equals, tt.sec)
+			}
+		})
+	}
+}
+
+func TestSecOfDayOf(t *testing.T) {
+	c := qt.New(t)
+	loc := loadLocation(c, "Europe/Rome")
+	d := NewDate(2019, time.October, 27)
+
+	// 01:30 + 3h of elapsed time is 03:30 on the wall clock after the
+	// clocks go back an hour.
+	start := NewSecOfDay(1, 30, 0).Time(d, loc)
+	c.Assert(SecOfDayOf(d, start.Add(3*time.Hour)), qt.Equals, NewSecOfDay(3, 30, 0))
+
+	// Times on the following day continue past 24:00.
+	c.Assert(SecOfDayOf(d, time.Date(2019, time.October, 28, 1, 0, 0, 0, loc)), qt.Equals, NewSecOfDay(25, 0, 0))
+}
+
+func TestTimeWindowBounds(t *testing.T) {
+	c := qt.New(t)
+	loc := loadLocation(c, "America/New_York")
+
+	tw := TimeWindow{StartSec: 0, EndSec: NewSecOfDay(3, 0, 0)}
+	start, end := tw.Bounds(NewDate(2019, time.November, 3), loc)
+	c.Assert(end.Sub(start), qt.Equals, 4*time.Hour)
+
+	start, end = tw.Bounds(NewDate(2019, time.March, 10), loc)
+	c.Assert(end.Sub(start), qt.Equals, 2*time.Hour)
+}
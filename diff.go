@@ -0,0 +1,424 @@
+package workwave
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultArrivalThreshold is the arrival shift below which DiffRoutes and
+// DiffPlans ignore changes to the arrival of a step.
+const DefaultArrivalThreshold = time.Minute
+
+// StepChangeKind is the kind of change made to a route step.
+type StepChangeKind int
+
+// Kinds of step changes.
+const (
+	StepAdded StepChangeKind = iota
+	StepRemoved
+	// StepMoved steps are in a different order relative to the steps which
+	// are in both revisions of the route.
+	StepMoved
+	// StepArrivalShifted steps are planned to be reached at a different time.
+	StepArrivalShifted
+	// StepStatusChanged steps have a different tracking status.
+	StepStatusChanged
+)
+
+var stepChangeKinds = []string{"added", "removed", "moved", "arrivalShifted", "statusChanged"}
+
+func (k StepChangeKind) String() string {
+	if k < 0 || int(k) >= len(stepChangeKinds) {
+		return fmt.Sprintf("StepChangeKind(%d)", int(k))
+	}
+	return stepChangeKinds[k]
+}
+
+// MarshalText encodes k as its name.
+func (k StepChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// StepChange is a change made to a route step. Steps are matched between
+// revisions by their type and order ID, or for steps which serve no order,
+// such as breaks, by their type and position among steps of that type.
+type StepChange struct {
+	Kind    StepChangeKind `json:"kind"`
+	Type    StepType       `json:"type"`
+	OrderID string         `json:"orderId,omitempty"`
+	// OldIndex and NewIndex are the positions of the step in the old and new
+	// route, or -1 if it is not in it.
+	OldIndex int `json:"oldIndex"`
+	NewIndex int `json:"newIndex"`
+	// OldArrival, NewArrival and Shift are set for StepArrivalShifted.
+	OldArrival SecOfDay      `json:"oldArrivalSec,omitempty"`
+	NewArrival SecOfDay      `json:"newArrivalSec,omitempty"`
+	Shift      time.Duration `json:"-"`
+	// OldStatus and NewStatus are set for StepStatusChanged, and are empty
+	// when the step was not tracked.
+	OldStatus TrackingStatus `json:"oldStatus,omitempty"`
+	NewStatus TrackingStatus `json:"newStatus,omitempty"`
+}
+
+// Reassignment is a change of the driver or vehicle of a route.
+type Reassignment struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// RouteDiff describes the changes between two revisions of a route.
+type RouteDiff struct {
+	RouteID     string        `json:"routeId"`
+	OldRevision int           `json:"oldRevision"`
+	NewRevision int           `json:"newRevision"`
+	Driver      *Reassignment `json:"driver,omitempty"`
+	Vehicle     *Reassignment `json:"vehicle,omitempty"`
+	// Steps are ordered by the position of the step in the new route, and
+	// removed steps by their position in the old route, after the others.
+	Steps []StepChange `json:"steps,omitempty"`
+}
+
+// Empty reports whether the revisions are the same, as far as the diff can
+// tell.
+func (d RouteDiff) Empty() bool {
+	return d.Driver == nil && d.Vehicle == nil && len(d.Steps) == 0
+}
+
+// OrderMove is an order step which moved from one route to another.
+type OrderMove struct {
+	OrderID     string   `json:"orderId"`
+	Type        StepType `json:"type"`
+	FromRouteID string   `json:"fromRouteId"`
+	ToRouteID   string   `json:"toRouteId"`
+}
+
+// PlanDiff describes the changes between two sets of routes, such as the
+// routes of a day listed at different times.
+type PlanDiff struct {
+	Added   []Route     `json:"added,omitempty"`
+	Removed []Route     `json:"removed,omitempty"`
+	Changed []RouteDiff `json:"changed,omitempty"`
+	// Moved are the order steps which were removed from one route and added
+	// to another. They are also reported as removed and added steps of the
+	// changed routes.
+	Moved []OrderMove `json:"moved,omitempty"`
+}
+
+// Empty reports whether the plans are the same, as far as the diff can tell.
+func (d PlanDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffOptions configures route diffs.
+type DiffOptions struct {
+	// ArrivalThreshold is the arrival shift below which changes to the
+	// arrival of a step are ignored. Negative thresholds report every shift.
+	ArrivalThreshold time.Duration
+}
+
+// DiffRoutes compares two revisions of a route, using the
+// DefaultArrivalThreshold.
+func DiffRoutes(old, new Route) RouteDiff {
+	return DiffOptions{ArrivalThreshold: DefaultArrivalThreshold}.DiffRoutes(old, new)
+}
+
+// DiffPlans compares two sets of routes, using the DefaultArrivalThreshold.
+func DiffPlans(old, new []Route) PlanDiff {
+	return DiffOptions{ArrivalThreshold: DefaultArrivalThreshold}.DiffPlans(old, new)
+}
+
+// DiffRoutes compares two revisions of a route.
+func (o DiffOptions) DiffRoutes(old, new Route) RouteDiff {
+	d := RouteDiff{
+		RouteID:     new.ID,
+		OldRevision: old.Revision,
+		NewRevision: new.Revision,
+	}
+	if old.DriverID != new.DriverID {
+		d.Driver = &Reassignment{Old: old.DriverID, New: new.DriverID}
+	}
+	if old.VehicleID != new.VehicleID {
+		d.Vehicle = &Reassignment{Old: old.VehicleID, New: new.VehicleID}
+	}
+
+	oldKeys := stepKeys(old.Steps)
+	newKeys := stepKeys(new.Steps)
+	oldIndex := make(map[string]int, len(oldKeys))
+	for n, k := range oldKeys {
+		oldIndex[k] = n
+	}
+	newIndex := make(map[string]int, len(newKeys))
+	for n, k := range newKeys {
+		newIndex[k] = n
+	}
+
+	// Steps in both revisions keep their order if they are part of the
+	// longest common subsequence of the two orders.
+	var oldCommon, newCommon []string
+	for _, k := range oldKeys {
+		if _, ok := newIndex[k]; ok {
+			oldCommon = append(oldCommon, k)
+		}
+	}
+	for _, k := range newKeys {
+		if _, ok := oldIndex[k]; ok {
+			newCommon = append(newCommon, k)
+		}
+	}
+	kept := longestCommonSubsequence(oldCommon, newCommon)
+
+	for n, k := range newKeys {
+		ns := new.Steps[n]
+		m, ok := oldIndex[k]
+		if !ok {
+			d.Steps = append(d.Steps, StepChange{Kind: StepAdded, Type: ns.Type, OrderID: ns.OrderID, OldIndex: -1, NewIndex: n})
+			continue
+		}
+		os := old.Steps[m]
+		change := StepChange{Type: ns.Type, OrderID: ns.OrderID, OldIndex: m, NewIndex: n}
+		if !kept[k] {
+			c := change
+			c.Kind = StepMoved
+			d.Steps = append(d.Steps, c)
+		}
+		if shift := ns.ArrivalSec.Sub(os.ArrivalSec); shift != 0 && (shift > o.ArrivalThreshold || -shift > o.ArrivalThreshold) {
+			c := change
+			c.Kind = StepArrivalShifted
+			c.OldArrival, c.NewArrival, c.Shift = os.ArrivalSec, ns.ArrivalSec, shift
+			d.Steps = append(d.Steps, c)
+		}
+		if from, to := trackingStatus(os), trackingStatus(ns); from != to {
+			c := change
+			c.Kind = StepStatusChanged
+			c.OldStatus, c.NewStatus = from, to
+			d.Steps = append(d.Steps, c)
+		}
+	}
+	for m, k := range oldKeys {
+		if _, ok := newIndex[k]; !ok {
+			os := old.Steps[m]
+			d.Steps = append(d.Steps, StepChange{Kind: StepRemoved, Type: os.Type, OrderID: os.OrderID, OldIndex: m, NewIndex: -1})
+		}
+	}
+	return d
+}
+
+// DiffPlans compares two sets of routes, which are matched by ID. Routes are
+// reported in order of their IDs.
+func (o DiffOptions) DiffPlans(old, new []Route) PlanDiff {
+	var d PlanDiff
+	oldByID := make(map[string]Route, len(old))
+	for _, r := range old {
+		oldByID[r.ID] = r
+	}
+	newByID := make(map[string]Route, len(new))
+	for _, r := range new {
+		newByID[r.ID] = r
+	}
+
+	for _, r := range sortedRoutes(new) {
+		or, ok := oldByID[r.ID]
+		if !ok {
+			d.Added = append(d.Added, r)
+			continue
+		}
+		if rd := o.DiffRoutes(or, r); !rd.Empty() {
+			d.Changed = append(d.Changed, rd)
+		}
+	}
+	for _, r := range sortedRoutes(old) {
+		if _, ok := newByID[r.ID]; !ok {
+			d.Removed = append(d.Removed, r)
+		}
+	}
+
+	// Order steps found on a different route than before have moved.
+	oldRoutes := orderStepRoutes(old)
+	for _, r := range sortedRoutes(new) {
+		for _, s := range r.Steps {
+			if s.OrderID == "" {
+				continue
+			}
+			from, ok := oldRoutes[stepKey(s, 0)]
+			if ok && from != r.ID {
+				d.Moved = append(d.Moved, OrderMove{OrderID: s.OrderID, Type: s.Type, FromRouteID: from, ToRouteID: r.ID})
+			}
+		}
+	}
+	return d
+}
+
+func sortedRoutes(routes []Route) []Route {
+	sorted := append([]Route(nil), routes...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+	return sorted
+}
+
+func orderStepRoutes(routes []Route) map[string]string {
+	m := make(map[string]string)
+	for _, r := range routes {
+		for _, s := range r.Steps {
+			if s.OrderID != "" {
+				m[stepKey(s, 0)] = r.ID
+			}
+		}
+	}
+	return m
+}
+
+// stepKeys returns keys identifying steps across revisions of a route.
+func stepKeys(steps []RouteStep) []string {
+	keys := make([]string, len(steps))
+	seen := make(map[StepType]int)
+	for n, s := range steps {
+		keys[n] = stepKey(s, seen[s.Type])
+		if s.OrderID == "" {
+			seen[s.Type]++
+		}
+	}
+	return keys
+}
+
+// stepKey identifies s by its order, or for steps which serve no order, by
+// its position among the route's steps of the same type.
+func stepKey(s RouteStep, n int) string {
+	if s.OrderID != "" {
+		return string(s.Type) + ":" + s.OrderID
+	}
+	return fmt.Sprintf("%s#%d", s.Type, n)
+}
+
+func trackingStatus(s RouteStep) TrackingStatus {
+	if s.TrackingData == nil {
+		return ""
+	}
+	return s.TrackingData.Status
+}
+
+// longestCommonSubsequence returns the keys of a longest subsequence common
+// to a and b, which hold the same keys in a different order.
+func longestCommonSubsequence(a, b []string) map[string]bool {
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	kept := make(map[string]bool, lengths[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			kept[a[i]] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return kept
+}
+
+// FieldChange is a change to a field of an order. Old and New are the JSON
+// encoded values of the field, and are nil when the field is not set.
+type FieldChange struct {
+	// Field is the path of the field, ie "delivery.timeWindows[1].endSec".
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// DiffOrder compares two versions of an order field by field, as they are
+// encoded to JSON. Changes are ordered by field.
+func DiffOrder(old, new Order) ([]FieldChange, error) {
+	oldFields, err := flattenJSON(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := flattenJSON(new)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for f, v := range newFields {
+		if ov, ok := oldFields[f]; !ok || !bytes.Equal(ov, v) {
+			changes = append(changes, FieldChange{Field: f, Old: oldFields[f], New: v})
+		}
+	}
+	for f, v := range oldFields {
+		if _, ok := newFields[f]; !ok {
+			changes = append(changes, FieldChange{Field: f, Old: v})
+		}
+	}
+	sort.Slice(changes, func(a, b int) bool { return changes[a].Field < changes[b].Field })
+	return changes, nil
+}
+
+// flattenJSON encodes v to JSON, returning the encoded value of each leaf by
+// its path.
+func flattenJSON(v interface{}) (map[string]json.RawMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode order")
+	}
+	var tree interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&tree); err != nil {
+		return nil, errors.Wrap(err, "failed to decode order")
+	}
+
+	fields := make(map[string]json.RawMessage)
+	var walk func(path string, v interface{}) error
+	walk = func(path string, v interface{}) error {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, e := range v {
+				p := k
+				if path != "" {
+					p = path + "." + k
+				}
+				if err := walk(p, e); err != nil {
+					return err
+				}
+			}
+			return nil
+		case []interface{}:
+			for n, e := range v {
+				if err := walk(fmt.Sprintf("%s[%d]", path, n), e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fields[path] = b
+		return nil
+	}
+	if err := walk("", tree); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
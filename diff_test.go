@@ -0,0 +1,175 @@
+package workwave
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func diffRoute() Route {
+	return Route{
+		ID:        "v1-20151204",
+		Revision:  1,
+		DriverID:  "d1",
+		VehicleID: "v1",
+		Steps: []RouteStep{
+			{Type: StepDeparture, EndSec: 28800},
+			{Type: StepDelivery, OrderID: "o1", ArrivalSec: 30000},
+			{Type: StepDelivery, OrderID: "o2", ArrivalSec: 32000},
+			{Type: StepBreak, ArrivalSec: 34000},
+			{Type: StepDelivery, OrderID: "o3", ArrivalSec: 36000},
+			{Type: StepArrival, ArrivalSec: 40000},
+		},
+	}
+}
+
+func TestDiffRoutes(t *testing.T) {
+	t.Run("unchanged", func(t *testing.T) {
+		c := qt.New(t)
+		d := DiffRoutes(diffRoute(), diffRoute())
+		c.Assert(d.Empty(), qt.Equals, true)
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		c := qt.New(t)
+		old := diffRoute()
+		new := diffRoute()
+		new.Revision = 2
+		new.DriverID = "d2"
+		// o3 is served before o2, o1 is removed and o4 added.
+		new.Steps = []RouteStep{
+			{Type: StepDeparture, EndSec: 28800},
+			{Type: StepPickup, OrderID: "o4", ArrivalSec: 29000},
+			{Type: StepDelivery, OrderID: "o3", ArrivalSec: 30030, TrackingData: &TrackingData{Status: TrackingDone}},
+			{Type: StepDelivery, OrderID: "o2", ArrivalSec: 32000},
+			{Type: StepBreak, ArrivalSec: 34000},
+			{Type: StepArrival, ArrivalSec: 39970},
+		}
+
+		d := DiffRoutes(old, new)
+		c.Assert(d, qt.DeepEquals, RouteDiff{
+			RouteID:     "v1-20151204",
+			OldRevision: 1,
+			NewRevision: 2,
+			Driver:      &Reassignment{Old: "d1", New: "d2"},
+			Steps: []StepChange{
+				{Kind: StepAdded, Type: StepPickup, OrderID: "o4", OldIndex: -1, NewIndex: 1},
+				{Kind: StepMoved, Type: StepDelivery, OrderID: "o3", OldIndex: 4, NewIndex: 2},
+				{Kind: StepArrivalShifted, Type: StepDelivery, OrderID: "o3", OldIndex: 4, NewIndex: 2, OldArrival: 36000, NewArrival: 30030, Shift: -5970 * time.Second},
+				{Kind: StepStatusChanged, Type: StepDelivery, OrderID: "o3", OldIndex: 4, NewIndex: 2, NewStatus: TrackingDone},
+				{Kind: StepRemoved, Type: StepDelivery, OrderID: "o1", OldIndex: 1, NewIndex: -1},
+			},
+		})
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		c := qt.New(t)
+		new := diffRoute()
+		new.Steps[5].ArrivalSec += 30
+		c.Assert(DiffRoutes(diffRoute(), new).Empty(), qt.Equals, true)
+
+		d := DiffOptions{ArrivalThreshold: 10 * time.Second}.DiffRoutes(diffRoute(), new)
+		c.Assert(d.Steps, qt.HasLen, 1)
+		c.Assert(d.Steps[0].Shift, qt.Equals, 30*time.Second)
+
+		d = DiffOptions{ArrivalThreshold: -1}.DiffRoutes(diffRoute(), new)
+		c.Assert(d.Steps, qt.HasLen, 1)
+	})
+
+	t.Run("vehicle", func(t *testing.T) {
+		c := qt.New(t)
+		new := diffRoute()
+		new.VehicleID = "v2"
+		d := DiffRoutes(diffRoute(), new)
+		c.Assert(d.Vehicle, qt.DeepEquals, &Reassignment{Old: "v1", New: "v2"})
+		c.Assert(d.Steps, qt.HasLen, 0)
+	})
+}
+
+func TestDiffRoutesJSON(t *testing.T) {
+	c := qt.New(t)
+	new := diffRoute()
+	new.Revision = 2
+	new.Steps[1].TrackingData = &TrackingData{Status: TrackingUndone}
+
+	b, err := json.Marshal(DiffRoutes(diffRoute(), new))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"routeId":"v1-20151204","oldRevision":1,"newRevision":2,"steps":[`+
+		`{"kind":"statusChanged","type":"delivery","orderId":"o1","oldIndex":1,"newIndex":1,"newStatus":"undone"}]}`)
+}
+
+func TestDiffPlans(t *testing.T) {
+	c := qt.New(t)
+	r1 := diffRoute()
+	r2 := Route{ID: "v2-20151204", Steps: []RouteStep{{Type: StepDeparture}, {Type: StepArrival}}}
+	r3 := Route{ID: "v3-20151204"}
+
+	// o2 moves from r1 to r2, r3 is removed and r4 added.
+	newR1 := diffRoute()
+	newR1.Steps = append(newR1.Steps[:2:2], newR1.Steps[3:]...)
+	newR2 := r2
+	newR2.Steps = []RouteStep{{Type: StepDeparture}, {Type: StepDelivery, OrderID: "o2"}, {Type: StepArrival}}
+	r4 := Route{ID: "v4-20151204"}
+
+	d := DiffPlans([]Route{r3, r2, r1}, []Route{r4, newR2, newR1})
+	c.Assert(d.Empty(), qt.Equals, false)
+	c.Assert(d.Added, qt.DeepEquals, []Route{r4})
+	c.Assert(d.Removed, qt.DeepEquals, []Route{r3})
+	c.Assert(d.Changed, qt.HasLen, 2)
+	c.Assert(d.Changed[0].RouteID, qt.Equals, "v1-20151204")
+	c.Assert(d.Changed[0].Steps, qt.DeepEquals, []StepChange{
+		{Kind: StepRemoved, Type: StepDelivery, OrderID: "o2", OldIndex: 2, NewIndex: -1},
+	})
+	c.Assert(d.Changed[1].RouteID, qt.Equals, "v2-20151204")
+	c.Assert(d.Moved, qt.DeepEquals, []OrderMove{
+		{OrderID: "o2", Type: StepDelivery, FromRouteID: "v1-20151204", ToRouteID: "v2-20151204"},
+	})
+
+	c.Assert(DiffPlans([]Route{r1, r2}, []Route{r2, r1}).Empty(), qt.Equals, true)
+}
+
+func TestStepChangeKind(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(StepArrivalShifted.String(), qt.Equals, "arrivalShifted")
+	c.Assert(StepChangeKind(9).String(), qt.Equals, "StepChangeKind(9)")
+}
+
+func TestDiffOrder(t *testing.T) {
+	c := qt.New(t)
+	old := Order{
+		ID:          "o1",
+		Name:        "Order 1",
+		Eligibility: Eligibility{Type: EligibilityAny},
+		Loads:       Loads{"frozen ton": 100},
+		Delivery: &OrderStep{
+			Location:    Location{Address: "710 7th Ave"},
+			TimeWindows: []TimeWindow{{StartSec: 30600, EndSec: 37800}},
+			Notes:       "Fragile",
+		},
+	}
+	new := old
+	new.Name = "Order 1b"
+	new.Loads = Loads{"regular ton": 50}
+	delivery := *old.Delivery
+	delivery.TimeWindows = []TimeWindow{{StartSec: 30600, EndSec: 36000}, {StartSec: 45000, EndSec: 55800}}
+	delivery.Notes = ""
+	new.Delivery = &delivery
+
+	changes, err := DiffOrder(old, new)
+	c.Assert(err, qt.IsNil)
+	c.Assert(changes, qt.DeepEquals, []FieldChange{
+		{Field: "delivery.notes", Old: json.RawMessage(`"Fragile"`)},
+		{Field: "delivery.timeWindows[0].endSec", Old: json.RawMessage(`37800`), New: json.RawMessage(`36000`)},
+		{Field: "delivery.timeWindows[1].endSec", New: json.RawMessage(`55800`)},
+		{Field: "delivery.timeWindows[1].startSec", New: json.RawMessage(`45000`)},
+		{Field: "loads.frozen ton", Old: json.RawMessage(`100`)},
+		{Field: "loads.regular ton", New: json.RawMessage(`50`)},
+		{Field: "name", Old: json.RawMessage(`"Order 1"`), New: json.RawMessage(`"Order 1b"`)},
+	})
+
+	changes, err = DiffOrder(old, old)
+	c.Assert(err, qt.IsNil)
+	c.Assert(changes, qt.HasLen, 0)
+}
@@ -0,0 +1,165 @@
+package workwave
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// enum is implemented by the enum types of the API. Values which are not known
+// are decoded and encoded as is, so that values added to the API later can
+// still be decoded and sent back. Use the Valid methods to check individual
+// values, or WithStrictEnums to check all values of a client's requests and
+// responses.
+type enum interface {
+	Valid() bool
+	enumName() string
+}
+
+// EligibilityType is the type of an order's Eligibility.
+type EligibilityType string
+
+// Eligibility types.
+const (
+	EligibilityOn  EligibilityType = "on"  // On any of the dates in OnDates
+	EligibilityBy  EligibilityType = "by"  // On or before ByDate
+	EligibilityAny EligibilityType = "any" // On any date
+)
+
+// String implements fmt.Stringer.
+func (t EligibilityType) String() string { return string(t) }
+
+// Valid reports whether t is a known eligibility type or empty.
+func (t EligibilityType) Valid() bool {
+	switch t {
+	case "", EligibilityOn, EligibilityBy, EligibilityAny:
+		return true
+	}
+	return false
+}
+
+// enumName implements enum.
+func (EligibilityType) enumName() string { return "eligibility type" }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *EligibilityType) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(t.enumName(), b, (*string)(t))
+}
+
+// StepType is the type of a RouteStep.
+type StepType string
+
+// Route step types. Orders can only be executed at pickup and delivery steps.
+const (
+	StepDeparture StepType = "departure"
+	StepArrival   StepType = "arrival"
+	StepPickup    StepType = "pickup"
+	StepDelivery  StepType = "delivery"
+	StepBreak     StepType = "brk"
+)
+
+// String implements fmt.Stringer.
+func (t StepType) String() string { return string(t) }
+
+// Valid reports whether t is a known step type or empty.
+func (t StepType) Valid() bool {
+	switch t {
+	case "", StepDeparture, StepArrival, StepPickup, StepDelivery, StepBreak:
+		return true
+	}
+	return false
+}
+
+// enumName implements enum.
+func (StepType) enumName() string { return "step type" }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *StepType) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(t.enumName(), b, (*string)(t))
+}
+
+// LocationStatus is the geocoding status of a Location.
+type LocationStatus string
+
+// Location statuses.
+const (
+	LocationOK                    LocationStatus = "OK"
+	LocationLowAccuracy           LocationStatus = "LOW_ACCURACY"
+	LocationPostcodeLevelAccuracy LocationStatus = "POSTCODE_LEVEL_ACCURACY"
+	LocationNotFound              LocationStatus = "NOT_FOUND"
+)
+
+// String implements fmt.Stringer.
+func (s LocationStatus) String() string { return string(s) }
+
+// Valid reports whether s is a known location status or empty.
+func (s LocationStatus) Valid() bool {
+	switch s {
+	case "", LocationOK, LocationLowAccuracy, LocationPostcodeLevelAccuracy, LocationNotFound:
+		return true
+	}
+	return false
+}
+
+// enumName implements enum.
+func (LocationStatus) enumName() string { return "location status" }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *LocationStatus) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(s.enumName(), b, (*string)(s))
+}
+
+// TrackingStatus is the execution status of an order step, as reported in
+// TrackingData and Execution.
+type TrackingStatus string
+
+// Tracking statuses. An empty status means the step has not been executed.
+const (
+	TrackingDone       TrackingStatus = "done"
+	TrackingUndone     TrackingStatus = "undone"
+	TrackingReschedule TrackingStatus = "reschedule"
+)
+
+// String implements fmt.Stringer.
+func (s TrackingStatus) String() string { return string(s) }
+
+// Valid reports whether s is a known tracking status or empty.
+func (s TrackingStatus) Valid() bool {
+	switch s {
+	case "", TrackingDone, TrackingUndone, TrackingReschedule:
+		return true
+	}
+	return false
+}
+
+// enumName implements enum.
+func (TrackingStatus) enumName() string { return "tracking status" }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *TrackingStatus) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(s.enumName(), b, (*string)(s))
+}
+
+func unmarshalEnum(name string, b []byte, dst *string) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", name)
+	}
+	*dst = v
+	return nil
+}
+
+// checkEnums returns an error for the first value reachable from v which is
+// not a known enum value.
+func checkEnums(v reflect.Value) error {
+	return walk(v, func(v reflect.Value) error {
+		if e, ok := v.Interface().(enum); ok && v.Kind() == reflect.String && !e.Valid() {
+			return errors.Errorf("invalid %s %q", e.enumName(), v.String())
+		}
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package workwave
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEnumsJSON(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		known interface{}
+		want  string
+		dst   func() interface{}
+	}{
+		{
+			name:  "eligibility type",
+			known: EligibilityBy,
+			want:  `"by"`,
+			dst:   func() interface{} { return new(EligibilityType) },
+		},
+		{
+			name:  "step type",
+			known: StepBreak,
+			want:  `"brk"`,
+			dst:   func() interface{} { return new(StepType) },
+		},
+		{
+			name:  "location status",
+			known: LocationLowAccuracy,
+			want:  `"LOW_ACCURACY"`,
+			dst:   func() interface{} { return new(LocationStatus) },
+		},
+		{
+			name:  "tracking status",
+			known: TrackingReschedule,
+			want:  `"reschedule"`,
+			dst:   func() interface{} { return new(TrackingStatus) },
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+
+			b, err := json.Marshal(tt.known)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(b), qt.Equals, tt.want)
+
+			dst := tt.dst()
+			c.Assert(json.Unmarshal(b, dst), qt.IsNil)
+			b, _ = json.Marshal(dst)
+			c.Assert(string(b), qt.Equals, tt.want)
+
+			dst = tt.dst()
+			c.Assert(json.Unmarshal([]byte(`"typo"`), dst), qt.IsNil)
+			c.Assert(dst.(enum).Valid(), qt.Equals, false)
+			err = checkEnums(reflect.ValueOf(dst))
+			c.Assert(err, qt.ErrorMatches, `invalid `+tt.name+` "typo"`)
+
+			err = json.Unmarshal([]byte(`1`), tt.dst())
+			c.Assert(err, qt.ErrorMatches, `failed to decode `+tt.name+`.*`)
+		})
+	}
+}
+
+func TestEnumsNull(t *testing.T) {
+	c := qt.New(t)
+	s := TrackingDone
+	c.Assert(json.Unmarshal([]byte(`null`), &s), qt.IsNil)
+	c.Assert(s, qt.Equals, TrackingDone)
+}
+
+func TestEnumsUnknown(t *testing.T) {
+	c := qt.New(t)
+
+	var step RouteStep
+	err := json.Unmarshal([]byte(`{"type": "refuel", "trackingData": {"status": "skipped"}}`), &step)
+	c.Assert(err, qt.IsNil)
+	c.Assert(step.Type, qt.Equals, StepType("refuel"))
+	c.Assert(step.Type.Valid(), qt.Equals, false)
+	c.Assert(step.TrackingData.Status, qt.Equals, TrackingStatus("skipped"))
+
+	b, err := json.Marshal(step)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.JSONEquals, map[string]interface{}{
+		"type": "refuel",
+		"trackingData": map[string]interface{}{
+			"status":             "skipped",
+			"timeInSec":          -1,
+			"timeOutSec":         -1,
+			"statusSec":          -1,
+			"timeInDetectedSec":  -1,
+			"timeOutDetectedSec": -1,
+		},
+	})
+}
+
+func TestStrictEnums(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	called := false
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		fmt.Fprint(w, `{"routes": {"r1": {"steps": [{"type": "newtype"}]}}}`)
+	})
+
+	input := RoutesListCurrentInput{TerritoryID: "territory"}
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+	routes, err := client.Routes.ListCurrent(ctx, input)
+	c.Assert(err, qt.IsNil)
+	c.Assert(routes[0].Steps[0].Type, qt.Equals, StepType("newtype"))
+
+	client, _ = New("api-key", WithStrictEnums())
+	client.baseURL, _ = url.Parse(server.URL)
+	_, err = client.Routes.ListCurrent(ctx, input)
+	c.Assert(err, qt.ErrorMatches, `failed to decode JSON: invalid step type "newtype"`)
+
+	called = false
+	_, err = client.Orders.Add(ctx, OrdersAddInput{
+		TerritoryID: "territory",
+		Orders:      []Order{{Name: "o", Eligibility: Eligibility{Type: "onn"}}},
+	})
+	c.Assert(err, qt.ErrorMatches, `.*invalid eligibility type "onn"`)
+	c.Assert(called, qt.Equals, false)
+}
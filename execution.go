@@ -0,0 +1,213 @@
+package workwave
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	executionPath          = ordersBasePath + "/%s/%s/execution"
+	executionPicturesPath  = executionPath + "/pictures"
+	executionSignaturePath = executionPath + "/signatures/%s"
+	attachmentPath         = "/api/v1/territories/%s/attachments/%s"
+
+	defaultPictureType   = "image/jpeg"
+	defaultSignatureType = "image/png"
+)
+
+// ExecutionService is an interface to the execution details recorded by
+// drivers against order steps in the WorkWave API.
+type ExecutionService interface {
+	Get(context.Context, ExecutionGetInput) (Execution, error)
+	Update(context.Context, ExecutionUpdateInput) (Execution, error)
+	AddPicture(context.Context, ExecutionPictureInput) (Attachment, error)
+	SetSignature(context.Context, ExecutionSignatureInput) (Attachment, error)
+	GetAttachment(context.Context, AttachmentGetInput, io.Writer) error
+}
+
+type executionService struct {
+	client *Client
+}
+
+// Execution holds the details recorded while executing an order step, such as
+// its status, driver notes, barcode scans and proof of delivery attachments.
+type Execution struct {
+	Status     TrackingStatus        `json:"status,omitempty"`
+	StatusSec  SecOfDay              `json:"statusSec,omitempty"`
+	Note       string                `json:"note,omitempty"`
+	Barcodes   []Barcode             `json:"barcodes,omitempty"`
+	Pictures   []Attachment          `json:"pictures,omitempty"`
+	Signatures map[string]Attachment `json:"signatures,omitempty"` // keyed by signer, ie "customer"
+}
+
+// Barcode is a barcode scanned by a driver while executing an order step.
+type Barcode struct {
+	Barcode string   `json:"barcode,omitempty"`
+	Status  string   `json:"status,omitempty"` // One of: scanned, missing
+	Sec     SecOfDay `json:"sec,omitempty"`
+}
+
+// Attachment references a binary file, such as a picture or signature,
+// stored by WorkWave. The content can be retrieved using its Token.
+type Attachment struct {
+	Token string   `json:"token,omitempty"`
+	Sec   SecOfDay `json:"sec,omitempty"`
+}
+
+// ExecutionGetInput is used to populate a call to Get Execution on the
+// WorkWave API.
+type ExecutionGetInput struct {
+	TerritoryID string
+	OrderID     string
+	Step        StepType // One of: StepPickup, StepDelivery
+}
+
+// Get retrieves the execution details of an order step.
+func (svc *executionService) Get(ctx context.Context, i ExecutionGetInput) (Execution, error) {
+	ex := Execution{}
+	if err := checkExecutionStep(i.Step); err != nil {
+		return ex, err
+	}
+
+	u := fmt.Sprintf(executionPath, i.TerritoryID, i.OrderID, i.Step)
+	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return ex, errors.Wrap(err, "failed to create execution get request")
+	}
+
+	if _, err := svc.client.Do(ctx, req, &ex); err != nil {
+		return ex, err
+	}
+	return ex, nil
+}
+
+// ExecutionUpdateInput is used to populate a call to Update Execution on the
+// WorkWave API. Empty fields are left unchanged.
+type ExecutionUpdateInput struct {
+	TerritoryID string         `json:"-"`
+	OrderID     string         `json:"-"`
+	Step        StepType       `json:"-"` // One of: StepPickup, StepDelivery
+	Status      TrackingStatus `json:"status,omitempty"`
+	Note        string         `json:"note,omitempty"`
+	Barcodes    []Barcode      `json:"barcodes,omitempty"`
+}
+
+// Update sets the status, note and barcodes of an order step and returns the
+// resulting execution details.
+func (svc *executionService) Update(ctx context.Context, i ExecutionUpdateInput) (Execution, error) {
+	ex := Execution{}
+	if err := checkExecutionStep(i.Step); err != nil {
+		return ex, err
+	}
+
+	u := fmt.Sprintf(executionPath, i.TerritoryID, i.OrderID, i.Step)
+	req, err := svc.client.NewRequest(ctx, http.MethodPost, u, i)
+	if err != nil {
+		return ex, errors.Wrap(err, "failed to create execution update request")
+	}
+
+	if _, err := svc.client.Do(ctx, req, &ex); err != nil {
+		return ex, err
+	}
+	return ex, nil
+}
+
+// ExecutionPictureInput is used to populate a call to Add Picture on the
+// WorkWave API. The picture is streamed from Body.
+type ExecutionPictureInput struct {
+	TerritoryID string
+	OrderID     string
+	Step        StepType // One of: StepPickup, StepDelivery
+	ContentType string   // Defaults to image/jpeg
+	Body        io.Reader
+}
+
+// AddPicture uploads a picture, such as a proof of delivery photo, to an
+// order step.
+func (svc *executionService) AddPicture(ctx context.Context, i ExecutionPictureInput) (Attachment, error) {
+	u := fmt.Sprintf(executionPicturesPath, i.TerritoryID, i.OrderID, i.Step)
+	ct := i.ContentType
+	if ct == "" {
+		ct = defaultPictureType
+	}
+	return svc.upload(ctx, u, i.Step, ct, i.Body, "picture")
+}
+
+// ExecutionSignatureInput is used to populate a call to Set Signature on the
+// WorkWave API. The signature image is streamed from Body.
+type ExecutionSignatureInput struct {
+	TerritoryID string
+	OrderID     string
+	Step        StepType // One of: StepPickup, StepDelivery
+	Signer      string   // ie, customer
+	ContentType string   // Defaults to image/png
+	Body        io.Reader
+}
+
+// SetSignature uploads the signature of the given signer for an order step,
+// replacing any previous signature by the same signer.
+func (svc *executionService) SetSignature(ctx context.Context, i ExecutionSignatureInput) (Attachment, error) {
+	if i.Signer == "" {
+		return Attachment{}, errors.New("signature requires a signer")
+	}
+
+	u := fmt.Sprintf(executionSignaturePath, i.TerritoryID, i.OrderID, i.Step, i.Signer)
+	ct := i.ContentType
+	if ct == "" {
+		ct = defaultSignatureType
+	}
+	return svc.upload(ctx, u, i.Step, ct, i.Body, "signature")
+}
+
+func (svc *executionService) upload(ctx context.Context, u string, step StepType, contentType string, body io.Reader, kind string) (Attachment, error) {
+	a := Attachment{}
+	if err := checkExecutionStep(step); err != nil {
+		return a, err
+	}
+	if body == nil {
+		return a, errors.Errorf("%s requires a body", kind)
+	}
+
+	req, err := svc.client.NewUploadRequest(ctx, http.MethodPost, u, contentType, body)
+	if err != nil {
+		return a, errors.Wrapf(err, "failed to create %s upload request", kind)
+	}
+
+	if _, err := svc.client.Do(ctx, req, &a); err != nil {
+		return a, err
+	}
+	return a, nil
+}
+
+// AttachmentGetInput is used to populate a call to Get Attachment on the
+// WorkWave API.
+type AttachmentGetInput struct {
+	TerritoryID string
+	Token       string
+}
+
+// GetAttachment streams the content of the attachment with the given token
+// into w.
+func (svc *executionService) GetAttachment(ctx context.Context, i AttachmentGetInput, w io.Writer) error {
+	u := fmt.Sprintf(attachmentPath, i.TerritoryID, i.Token)
+	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create attachment get request")
+	}
+	req.Header.Set("Accept", "*/*")
+
+	_, err = svc.client.Do(ctx, req, w)
+	return err
+}
+
+func checkExecutionStep(step StepType) error {
+	switch step {
+	case StepPickup, StepDelivery:
+		return nil
+	}
+	return errors.Errorf("invalid execution step %q", step)
+}
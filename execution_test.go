@@ -0,0 +1,192 @@
+package workwave
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const executionTestPath = "/api/v1/territories/territory/orders/order-1/delivery/execution"
+
+func TestExecutionGet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(executionTestPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"status": "done",
+			"statusSec": 35912,
+			"note": "Left with reception",
+			"barcodes": [{"barcode": "0123456789", "status": "scanned", "sec": 35800}],
+			"pictures": [{"token": "pic-1", "sec": 35850}],
+			"signatures": {"customer": {"token": "sig-1", "sec": 35900}}
+		}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+		ex, err := client.Execution.Get(ctx, ExecutionGetInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "delivery",
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(ex, qt.DeepEquals, Execution{
+			Status:     "done",
+			StatusSec:  35912,
+			Note:       "Left with reception",
+			Barcodes:   []Barcode{{Barcode: "0123456789", Status: "scanned", Sec: 35800}},
+			Pictures:   []Attachment{{Token: "pic-1", Sec: 35850}},
+			Signatures: map[string]Attachment{"customer": {Token: "sig-1", Sec: 35900}},
+		})
+	})
+
+	t.Run("invalid step", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := client.Execution.Get(ctx, ExecutionGetInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "departure",
+		})
+		c.Assert(err, qt.ErrorMatches, `invalid execution step "departure"`)
+	})
+}
+
+func TestExecutionUpdate(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc(executionTestPath, func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, qt.Equals, http.MethodPost)
+		rBody, _ := ioutil.ReadAll(r.Body)
+		c.Check(string(rBody), qt.JSONEquals, map[string]interface{}{
+			"status": "reschedule",
+			"note":   "Customer not home",
+		})
+		fmt.Fprint(w, `{"status": "reschedule", "statusSec": 36000, "note": "Customer not home"}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	ex, err := client.Execution.Update(ctx, ExecutionUpdateInput{
+		TerritoryID: "territory",
+		OrderID:     "order-1",
+		Step:        "delivery",
+		Status:      "reschedule",
+		Note:        "Customer not home",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(ex.Status, qt.Equals, TrackingReschedule)
+	c.Assert(ex.StatusSec, qt.Equals, SecOfDay(36000))
+}
+
+func TestExecutionUploads(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var gotType string
+	var gotBody []byte
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("Content-Type")
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(Attachment{Token: "token-1", Sec: 36000})
+	}
+	mux.HandleFunc(executionTestPath+"/pictures", handler)
+	mux.HandleFunc(executionTestPath+"/signatures/customer", handler)
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	t.Run("picture, streamed", func(t *testing.T) {
+		c := qt.New(t)
+		pr, pw := io.Pipe()
+		go func() {
+			for i := 0; i < 3; i++ {
+				pw.Write([]byte("chunk"))
+			}
+			pw.Close()
+		}()
+
+		a, err := client.Execution.AddPicture(ctx, ExecutionPictureInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "delivery",
+			Body:        pr,
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(a, qt.DeepEquals, Attachment{Token: "token-1", Sec: 36000})
+		c.Assert(gotType, qt.Equals, "image/jpeg")
+		c.Assert(string(gotBody), qt.Equals, "chunkchunkchunk")
+	})
+
+	t.Run("signature", func(t *testing.T) {
+		c := qt.New(t)
+		a, err := client.Execution.SetSignature(ctx, ExecutionSignatureInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "delivery",
+			Signer:      "customer",
+			Body:        strings.NewReader("\x89PNG"),
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(a.Token, qt.Equals, "token-1")
+		c.Assert(gotType, qt.Equals, "image/png")
+		c.Assert(string(gotBody), qt.Equals, "\x89PNG")
+	})
+
+	t.Run("signature without signer", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := client.Execution.SetSignature(ctx, ExecutionSignatureInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "delivery",
+			Body:        strings.NewReader("\x89PNG"),
+		})
+		c.Assert(err, qt.ErrorMatches, "signature requires a signer")
+	})
+
+	t.Run("missing body", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := client.Execution.AddPicture(ctx, ExecutionPictureInput{
+			TerritoryID: "territory",
+			OrderID:     "order-1",
+			Step:        "delivery",
+		})
+		c.Assert(err, qt.ErrorMatches, "picture requires a body")
+	})
+}
+
+func TestExecutionGetAttachment(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/api/v1/territories/territory/attachments/sig-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		fmt.Fprint(w, "\x89PNG-data")
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	buf := new(bytes.Buffer)
+	err := client.Execution.GetAttachment(ctx, AttachmentGetInput{
+		TerritoryID: "territory",
+		Token:       "sig-1",
+	}, buf)
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, "\x89PNG-data")
+}
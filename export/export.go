@@ -0,0 +1,48 @@
+// Package export renders WorkWave orders and routes in formats understood by
+// other tools, such as GeoJSON for GIS applications, GPX for GPS navigation
+// devices, KML for Google Earth and iCalendar for calendar apps, and as
+// printable route manifests.
+package export
+
+import (
+	"strings"
+
+	workwave "github.com/tkh/go-workwave"
+)
+
+// stopLatLng returns the coordinates of the location of s, if known.
+func stopLatLng(s workwave.PlanStep) (workwave.LatLng, bool) {
+	if s.OrderStep == nil || s.OrderStep.Location.LatLng == nil {
+		return workwave.LatLng{}, false
+	}
+	return *s.OrderStep.Location.LatLng, true
+}
+
+// stopLabel names s by its display label and order name, ie "1.2 Order 7".
+func stopLabel(s workwave.PlanStep) string {
+	name := s.OrderID
+	if s.Order != nil {
+		name = s.Order.Name
+	}
+	switch {
+	case s.DisplayLabel == "":
+		return name
+	case name == "":
+		return s.DisplayLabel
+	}
+	return s.DisplayLabel + " " + name
+}
+
+// stopDescription returns the address and notes of s, one per line.
+func stopDescription(s workwave.PlanStep) string {
+	if s.OrderStep == nil {
+		return ""
+	}
+	var lines []string
+	for _, l := range []string{s.OrderStep.Location.Address, s.OrderStep.Notes} {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
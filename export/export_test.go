@@ -0,0 +1,55 @@
+package export
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+	"github.com/tkh/go-workwave/internal/fixture"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// golden compares got with the named file in testdata, which is rewritten
+// instead when the tests are run with -update.
+func golden(c *qt.C, name string, got []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		c.Assert(ioutil.WriteFile(path, got, 0644), qt.IsNil)
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(got), qt.Equals, string(want))
+}
+
+func TestStops(t *testing.T) {
+	c := qt.New(t)
+	ss := fixturePlans(c)[0].Steps
+
+	c.Assert(len(ss), qt.Equals, 4)
+	c.Assert(stopLabel(ss[0]), qt.Equals, "")
+	c.Assert(stopLabel(ss[1]), qt.Equals, "1.1 Order 1")
+	c.Assert(stopDescription(ss[1]), qt.Equals, "3101-3199 Florida Ave, Jasper, AL 35501, USA\n"+
+		"demonstrate the concept of multiple time windows as well as eligibility date range")
+	ll, ok := stopLatLng(ss[2])
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(ll, qt.Equals, workwave.LatLng{33843834, -87315561})
+	_, ok = stopLatLng(ss[3])
+	c.Assert(ok, qt.Equals, false)
+
+	// Steps whose order is missing are labelled by order ID.
+	s := ss[2]
+	s.Order, s.OrderStep = nil, nil
+	c.Assert(stopLabel(s), qt.Equals, "1.6 65413bab-aea8-41af-8992-487a32f50a59")
+	c.Assert(stopDescription(s), qt.Equals, "")
+}
+
+// fixturePlans returns the routes of the approved routes fixture joined with
+// their orders, drivers and vehicles.
+func fixturePlans(c *qt.C) []workwave.RoutePlan {
+	return fixture.Load(c, "routes-list-approved.json").Plans(c)
+}
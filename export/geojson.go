@@ -0,0 +1,151 @@
+package export
+
+import (
+	workwave "github.com/tkh/go-workwave"
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection, which is encoded by
+// encoding/json as specified by RFC 7946.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// Feature is a GeoJSON Feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry is a GeoJSON Point or LineString geometry. Coordinates are
+// [longitude, latitude] pairs, in degrees.
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func newFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{Type: "FeatureCollection", Features: []*Feature{}}
+}
+
+func newFeature(g *Geometry, props map[string]interface{}) *Feature {
+	return &Feature{Type: "Feature", Geometry: g, Properties: props}
+}
+
+func position(ll workwave.LatLng) []float64 {
+	return []float64{ll.Lng(), ll.Lat()}
+}
+
+func point(ll workwave.LatLng) *Geometry {
+	return &Geometry{Type: "Point", Coordinates: position(ll)}
+}
+
+// OrdersGeoJSON returns a FeatureCollection with a Point for each pickup and
+// delivery of orders. Steps whose location has not been geocoded are left
+// out, as they cannot be placed.
+//
+// The properties of each feature are the order's id, name and priority, the
+// step type, address, time windows (as HH:MM-HH:MM), tagsIn and tagsOut.
+func OrdersGeoJSON(orders []workwave.Order) *FeatureCollection {
+	fc := newFeatureCollection()
+	for _, o := range orders {
+		for _, s := range []struct {
+			typ  workwave.StepType
+			step *workwave.OrderStep
+		}{
+			{workwave.StepPickup, o.Pickup},
+			{workwave.StepDelivery, o.Delivery},
+		} {
+			if s.step == nil || s.step.Location.LatLng == nil {
+				continue
+			}
+			fc.Features = append(fc.Features, newFeature(point(*s.step.Location.LatLng), map[string]interface{}{
+				"orderId":     o.ID,
+				"name":        o.Name,
+				"priority":    o.Priority,
+				"type":        s.typ.String(),
+				"address":     s.step.Location.Address,
+				"timeWindows": timeWindows(s.step.TimeWindows),
+				"tagsIn":      nonNil(s.step.TagsIn),
+				"tagsOut":     nonNil(s.step.TagsOut),
+			}))
+		}
+	}
+	return fc
+}
+
+// RouteGeoJSON returns a FeatureCollection describing the route of p, whose
+// steps are located by their orders. The first feature is a
+// LineString through the located steps in route order, followed by a Point
+// for each located step. A LineString needs two positions, so the first
+// feature has a null geometry when fewer than two steps are located.
+//
+// The line has the route's routeId, vehicleId, driverId, date and revision as
+// properties. Each point has the step's stepIdx within the route, type,
+// orderId, order name, displayLabel, arrival (as a time of day, and as
+// arrivalSec) and the tracking status, which is empty until the step has been
+// tracked. Steps without a known location, such as departures and arrivals,
+// are left out.
+func RouteGeoJSON(p workwave.RoutePlan) *FeatureCollection {
+	var line [][]float64
+	var points []*Feature
+	for n, s := range p.Steps {
+		ll, ok := stopLatLng(s)
+		if !ok {
+			continue
+		}
+		line = append(line, position(ll))
+
+		name := ""
+		if s.Order != nil {
+			name = s.Order.Name
+		}
+		status := ""
+		if s.TrackingData != nil {
+			status = s.TrackingData.Status.String()
+		}
+		points = append(points, newFeature(point(ll), map[string]interface{}{
+			"stepIdx":      n,
+			"type":         s.Type.String(),
+			"orderId":      s.OrderID,
+			"name":         name,
+			"displayLabel": s.DisplayLabel,
+			"arrival":      s.ArrivalSec.String(),
+			"arrivalSec":   int(s.ArrivalSec),
+			"status":       status,
+		}))
+	}
+
+	var g *Geometry
+	if len(line) >= 2 {
+		g = &Geometry{Type: "LineString", Coordinates: line}
+	}
+	fc := newFeatureCollection()
+	fc.Features = append(fc.Features, newFeature(g, map[string]interface{}{
+		"routeId":   p.Route.ID,
+		"vehicleId": p.Route.VehicleID,
+		"driverId":  p.Route.DriverID,
+		"date":      p.Route.Date.String(),
+		"revision":  p.Route.Revision,
+	}))
+	fc.Features = append(fc.Features, points...)
+	return fc
+}
+
+func timeWindows(tws []workwave.TimeWindow) []string {
+	s := make([]string, len(tws))
+	for n, tw := range tws {
+		s[n] = tw.StartSec.String() + "-" + tw.EndSec.String()
+	}
+	return s
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that it is encoded as
+// an empty array rather than null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+	"github.com/tkh/go-workwave/internal/fixture"
+)
+
+func TestOrdersGeoJSON(t *testing.T) {
+	c := qt.New(t)
+	orders := fixture.Load(c, "routes-list-approved.json").SortedOrders()
+
+	b, err := json.MarshalIndent(OrdersGeoJSON(orders), "", "  ")
+	c.Assert(err, qt.IsNil)
+	golden(c, "orders.geojson", append(b, '\n'))
+
+	// Steps which were not geocoded are left out.
+	fc := OrdersGeoJSON([]workwave.Order{{
+		Name:     "Not geocoded",
+		Delivery: &workwave.OrderStep{Location: workwave.Location{Address: "Nowhere"}},
+	}})
+	c.Assert(fc.Features, qt.HasLen, 0)
+	b, err = json.Marshal(fc)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"type":"FeatureCollection","features":[]}`)
+}
+
+func TestRouteGeoJSON(t *testing.T) {
+	c := qt.New(t)
+	for _, p := range fixturePlans(c) {
+		fc := RouteGeoJSON(p)
+		b, err := json.MarshalIndent(fc, "", "  ")
+		c.Assert(err, qt.IsNil)
+		golden(c, p.Route.ID+".geojson", append(b, '\n'))
+
+		line := fc.Features[0]
+		if len(fc.Features) < 3 {
+			c.Assert(line.Geometry, qt.IsNil)
+			continue
+		}
+		c.Assert(line.Geometry.Type, qt.Equals, "LineString")
+		c.Assert(line.Geometry.Coordinates, qt.HasLen, len(fc.Features)-1)
+	}
+}
+
+func TestRouteGeoJSONTracking(t *testing.T) {
+	c := qt.New(t)
+	ll := workwave.LatLng{33817872, -87266893}
+	o := &workwave.Order{
+		ID:       "o1",
+		Name:     "Order 1",
+		Delivery: &workwave.OrderStep{Location: workwave.Location{LatLng: &ll}},
+	}
+	fc := RouteGeoJSON(workwave.RoutePlan{
+		Route: workwave.Route{ID: "r1"},
+		Steps: []workwave.PlanStep{
+			{RouteStep: workwave.RouteStep{Type: workwave.StepDeparture}},
+			{
+				RouteStep: workwave.RouteStep{
+					Type:         workwave.StepDelivery,
+					OrderID:      "o1",
+					ArrivalSec:   32689,
+					DisplayLabel: "1.1",
+					TrackingData: &workwave.TrackingData{Status: workwave.TrackingDone},
+				},
+				Order:     o,
+				OrderStep: o.Delivery,
+			},
+		},
+	})
+
+	c.Assert(fc.Features, qt.HasLen, 2)
+	// A single located step makes no line.
+	c.Assert(fc.Features[0].Geometry, qt.IsNil)
+	b, err := json.Marshal(fc.Features[0])
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Matches, `\{"type":"Feature","geometry":null,.*`)
+	c.Assert(fc.Features[1].Geometry.Coordinates, qt.DeepEquals, []float64{-87.266893, 33.817872})
+	c.Assert(fc.Features[1].Properties, qt.DeepEquals, map[string]interface{}{
+		"stepIdx":      1,
+		"type":         "delivery",
+		"orderId":      "o1",
+		"name":         "Order 1",
+		"displayLabel": "1.1",
+		"arrival":      "09:04:49",
+		"arrivalSec":   32689,
+		"status":       "done",
+	})
+}
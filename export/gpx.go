@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+type gpx struct {
+	XMLName  xml.Name   `xml:"http://www.topografix.com/GPX/1/1 gpx"`
+	Version  string     `xml:"version,attr"`
+	Creator  string     `xml:"creator,attr"`
+	Name     string     `xml:"metadata>name"`
+	Waypoint []gpxPoint `xml:"wpt"`
+	Route    gpxRoute   `xml:"rte"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxPoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Name string `xml:"name"`
+	Desc string `xml:"desc,omitempty"`
+	Type string `xml:"type"`
+}
+
+// WriteGPX writes the route of p as a GPX 1.1 document for GPS navigation
+// devices. Each step whose location is known from its order is written both
+// as a waypoint and as a point of the route, in route order. Points are named after the step's
+// display label and order name, and described by the address and notes of the
+// order step.
+func WriteGPX(w io.Writer, p workwave.RoutePlan) error {
+	doc := gpx{
+		Version: "1.1",
+		Creator: "go-workwave",
+		Name:    p.Route.ID,
+		Route:   gpxRoute{Name: p.Route.ID},
+	}
+	for _, s := range p.Steps {
+		ll, ok := stopLatLng(s)
+		if !ok {
+			continue
+		}
+		pt := gpxPoint{
+			Lat:  coordinate(ll.Lat()),
+			Lon:  coordinate(ll.Lng()),
+			Name: stopLabel(s),
+			Desc: stopDescription(s),
+			Type: s.Type.String(),
+		}
+		doc.Waypoint = append(doc.Waypoint, pt)
+		doc.Route.Points = append(doc.Route.Points, pt)
+	}
+	if err := writeXML(w, doc); err != nil {
+		return errors.Wrap(err, "failed to write GPX")
+	}
+	return nil
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func coordinate(deg float64) string {
+	return fmt.Sprintf("%.6f", deg)
+}
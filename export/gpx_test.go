@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+func TestWriteGPX(t *testing.T) {
+	c := qt.New(t)
+	for _, p := range fixturePlans(c) {
+		var b bytes.Buffer
+		c.Assert(WriteGPX(&b, p), qt.IsNil)
+		golden(c, p.Route.ID+".gpx", b.Bytes())
+
+		var doc gpx
+		c.Assert(xml.Unmarshal(b.Bytes(), &doc), qt.IsNil)
+		c.Assert(doc.Route.Points, qt.DeepEquals, doc.Waypoint)
+	}
+}
+
+func TestWriteGPXError(t *testing.T) {
+	c := qt.New(t)
+	err := WriteGPX(failingWriter{}, workwave.RoutePlan{})
+	c.Assert(err, qt.ErrorMatches, "failed to write GPX: write failed")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+const (
+	icsTimeFormat = "20060102T150405Z"
+	icsLineLimit  = 75 // octets, excluding the line break
+)
+
+// ICSOptions configures WriteICS.
+type ICSOptions struct {
+	// Location is the time zone of the route's territory, which its times of
+	// day are in. Defaults to UTC.
+	Location *time.Location
+	// Domain qualifies event UIDs, so that they are globally unique.
+	// Defaults to "workwave".
+	Domain string
+	// Stamp is the time at which the calendar was created. Defaults to now.
+	Stamp time.Time
+}
+
+// WriteICS writes the route of p as an iCalendar (RFC 5545) feed, with an
+// event per step which lasts from its StartSec to its EndSec. Departures are
+// instead placed at the time the vehicle leaves the depot.
+//
+// Event UIDs are derived from the route ID and the step's stopIdx, so that
+// importing an updated route replaces its events rather than duplicating
+// them, and the route revision is used as the event sequence. Events are
+// located at the address of the order step, or at its coordinates if it has
+// no address.
+func WriteICS(w io.Writer, p workwave.RoutePlan, o ICSOptions) error {
+	loc := o.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	domain := o.Domain
+	if domain == "" {
+		domain = "workwave"
+	}
+	stamp := o.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	iw := &icsWriter{w: bufio.NewWriter(w)}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//go-workwave//route export//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	iw.line("METHOD", "PUBLISH")
+	name := p.Route.ID
+	if p.Driver != nil && p.Driver.Name != "" {
+		name = p.Driver.Name + " " + p.Route.Date.String()
+	}
+	iw.text("X-WR-CALNAME", name)
+
+	// Steps at the same location share a stopIdx, and are told apart by
+	// their position within the stop.
+	atStop := make(map[int]int)
+	for _, s := range p.Steps {
+		uid := fmt.Sprintf("%s-%d", p.Route.ID, s.StopIdx)
+		if n := atStop[s.StopIdx]; n > 0 {
+			uid += fmt.Sprintf("-%d", n)
+		}
+		atStop[s.StopIdx]++
+
+		start, end := s.StartSec, s.EndSec
+		if s.Type == workwave.StepDeparture {
+			start = end
+		}
+
+		iw.line("BEGIN", "VEVENT")
+		iw.text("UID", uid+"@"+domain)
+		iw.line("DTSTAMP", stamp.UTC().Format(icsTimeFormat))
+		iw.line("DTSTART", start.Time(p.Route.Date, loc).UTC().Format(icsTimeFormat))
+		iw.line("DTEND", end.Time(p.Route.Date, loc).UTC().Format(icsTimeFormat))
+		iw.line("SEQUENCE", fmt.Sprint(p.Route.Revision))
+		iw.text("SUMMARY", icsSummary(s))
+		if s.OrderStep != nil {
+			if ll := s.OrderStep.Location.LatLng; ll != nil {
+				if s.OrderStep.Location.Address == "" {
+					iw.text("LOCATION", ll.String())
+				}
+				iw.line("GEO", fmt.Sprintf("%.6f;%.6f", ll.Lat(), ll.Lng()))
+			}
+			if s.OrderStep.Location.Address != "" {
+				iw.text("LOCATION", s.OrderStep.Location.Address)
+			}
+			if desc := icsDescription(p.Route.Date, s.OrderStep); desc != "" {
+				iw.text("DESCRIPTION", desc)
+			}
+		}
+		iw.line("END", "VEVENT")
+	}
+	iw.line("END", "VCALENDAR")
+
+	if iw.err == nil {
+		iw.err = iw.w.Flush()
+	}
+	if iw.err != nil {
+		return errors.Wrap(iw.err, "failed to write ICS")
+	}
+	return nil
+}
+
+func icsSummary(s workwave.PlanStep) string {
+	switch s.Type {
+	case workwave.StepDeparture:
+		return "Departure"
+	case workwave.StepArrival:
+		return "Arrival"
+	case workwave.StepBreak:
+		return "Break"
+	}
+	name := s.OrderID
+	if s.Order != nil {
+		name = s.Order.Name
+	}
+	if s.DisplayLabel != "" {
+		name = s.DisplayLabel + " " + name
+	}
+	return fmt.Sprintf("%s (%s)", name, s.Type)
+}
+
+func icsDescription(d workwave.Date, s *workwave.OrderStep) string {
+	var lines []string
+	if tws := s.TimeWindowsOn(d); len(tws) > 0 {
+		lines = append(lines, "Time windows: "+windowsString(tws))
+	}
+	if s.Notes != "" {
+		lines = append(lines, s.Notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// icsWriter writes content lines, folding them at 75 octets. The first error
+// is kept, and later writes are skipped.
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// text writes a property whose value is escaped as TEXT.
+func (iw *icsWriter) text(name, value string) {
+	value = strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+	iw.line(name, value)
+}
+
+func (iw *icsWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	l := name + ":" + value
+	limit := icsLineLimit
+	for len(l) > limit {
+		// Fold between characters rather than within them.
+		n := limit
+		for n > 0 && !utf8.RuneStart(l[n]) {
+			n--
+		}
+		if _, iw.err = iw.w.WriteString(l[:n] + "\r\n "); iw.err != nil {
+			return
+		}
+		l = l[n:]
+		limit = icsLineLimit - 1 // the folding space counts
+	}
+	_, iw.err = iw.w.WriteString(l + "\r\n")
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+var icsStamp = time.Date(2015, time.December, 3, 18, 0, 0, 0, time.UTC)
+
+func TestWriteICS(t *testing.T) {
+	c := qt.New(t)
+	loc, err := time.LoadLocation("America/Chicago")
+	c.Assert(err, qt.IsNil)
+
+	for _, p := range fixturePlans(c) {
+		var b bytes.Buffer
+		c.Assert(WriteICS(&b, p, ICSOptions{Location: loc, Domain: "example.com", Stamp: icsStamp}), qt.IsNil)
+		golden(c, p.Route.ID+".ics", b.Bytes())
+
+		for _, l := range strings.Split(b.String(), "\r\n") {
+			c.Assert(len(l) <= 75, qt.Equals, true, qt.Commentf("line %q", l))
+		}
+	}
+}
+
+func TestWriteICSStableUIDs(t *testing.T) {
+	c := qt.New(t)
+	p := fixturePlans(c)[0]
+
+	var before bytes.Buffer
+	c.Assert(WriteICS(&before, p, ICSOptions{Stamp: icsStamp}), qt.IsNil)
+
+	// Rescheduling a step keeps its UID, and bumps the sequence.
+	p.Route.Revision++
+	p.Steps[1].StartSec += 600
+	p.Steps[1].EndSec += 600
+	var after bytes.Buffer
+	c.Assert(WriteICS(&after, p, ICSOptions{Stamp: icsStamp}), qt.IsNil)
+	c.Assert(uids(after.String()), qt.DeepEquals, uids(before.String()))
+	c.Assert(strings.Contains(after.String(), "SEQUENCE:167\r\n"), qt.Equals, true)
+}
+
+func TestWriteICSSharedStop(t *testing.T) {
+	c := qt.New(t)
+	p := workwave.RoutePlan{
+		Route: workwave.Route{ID: "r1", Date: workwave.NewDate(2015, time.December, 4)},
+		Steps: []workwave.PlanStep{
+			{RouteStep: workwave.RouteStep{Type: workwave.StepDelivery, OrderID: "o1", StopIdx: 1, StartSec: 36000, EndSec: 36600}},
+			{RouteStep: workwave.RouteStep{Type: workwave.StepDelivery, OrderID: "o2", StopIdx: 1, StartSec: 36600, EndSec: 37200}},
+		},
+	}
+	var b bytes.Buffer
+	c.Assert(WriteICS(&b, p, ICSOptions{Stamp: icsStamp}), qt.IsNil)
+	c.Assert(uids(b.String()), qt.DeepEquals, []string{"r1-1@workwave", "r1-1-1@workwave"})
+	c.Assert(strings.Contains(b.String(), "DTSTART:20151204T100000Z\r\n"), qt.Equals, true)
+}
+
+func TestICSWriter(t *testing.T) {
+	c := qt.New(t)
+	var b bytes.Buffer
+	iw := &icsWriter{w: bufio.NewWriter(&b)}
+	iw.text("DESCRIPTION", "a;b,c\\d\ne")
+	iw.text("SUMMARY", strings.Repeat("é", 40))
+	c.Assert(iw.w.Flush(), qt.IsNil)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	c.Assert(lines[0], qt.Equals, `DESCRIPTION:a\;b\,c\\d\ne`)
+	c.Assert(lines[1], qt.Equals, "SUMMARY:"+strings.Repeat("é", 33))
+	c.Assert(lines[2], qt.Equals, " "+strings.Repeat("é", 7))
+}
+
+func uids(ics string) []string {
+	var uids []string
+	for _, l := range strings.Split(ics, "\r\n") {
+		if strings.HasPrefix(l, "UID:") {
+			uids = append(uids, strings.TrimPrefix(l, "UID:"))
+		}
+	}
+	return uids
+}
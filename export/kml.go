@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// kmlLineColors are the colors of successive route lines, in KML's aabbggrr
+// notation.
+var kmlLineColors = []string{
+	"ffd18a1f", // blue
+	"ff2c27d6", // red
+	"ff2ca02c", // green
+	"ff0e7fff", // orange
+	"ffbd6794", // purple
+	"ff4b568c", // brown
+}
+
+type kml struct {
+	XMLName  xml.Name    `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	LineStyle *kmlLineStyle `xml:"LineStyle,omitempty"`
+	IconStyle *kmlIconStyle `xml:"IconStyle,omitempty"`
+}
+
+type kmlLineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+type kmlIconStyle struct {
+	Href string `xml:"Icon>href"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	StyleURL    string         `xml:"styleUrl"`
+	Point       *kmlGeometry   `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlGeometry struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes the routes of plans as a KML 2.2 document for Google Earth.
+// Each route is a folder holding a line through its stops, drawn in a color
+// of its own, followed by a placemark for each stop. Stop locations are taken
+// from their orders, and stops are named and described as by WriteGPX.
+func WriteKML(w io.Writer, plans []workwave.RoutePlan) error {
+	doc := kml{Document: kmlDocument{
+		Name: "WorkWave routes",
+		Styles: []kmlStyle{{
+			ID:        "stop",
+			IconStyle: &kmlIconStyle{Href: "http://maps.google.com/mapfiles/kml/paddle/wht-circle.png"},
+		}},
+	}}
+	for n, p := range plans {
+		style := kmlStyle{
+			ID:        "route" + strconv.Itoa(n),
+			LineStyle: &kmlLineStyle{Color: kmlLineColors[n%len(kmlLineColors)], Width: 4},
+		}
+		doc.Document.Styles = append(doc.Document.Styles, style)
+
+		var coords []string
+		var points []kmlPlacemark
+		for _, s := range p.Steps {
+			ll, ok := stopLatLng(s)
+			if !ok {
+				continue
+			}
+			c := coordinate(ll.Lng()) + "," + coordinate(ll.Lat())
+			coords = append(coords, c)
+			points = append(points, kmlPlacemark{
+				Name:        stopLabel(s),
+				Description: stopDescription(s),
+				StyleURL:    "#stop",
+				Point:       &kmlGeometry{Coordinates: c},
+			})
+		}
+
+		folder := kmlFolder{Name: p.Route.ID}
+		folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+			Name:       p.Route.ID,
+			StyleURL:   "#" + style.ID,
+			LineString: &kmlLineString{Tessellate: 1, Coordinates: strings.Join(coords, " ")},
+		})
+		folder.Placemarks = append(folder.Placemarks, points...)
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+	if err := writeXML(w, doc); err != nil {
+		return errors.Wrap(err, "failed to write KML")
+	}
+	return nil
+}
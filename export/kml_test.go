@@ -0,0 +1,25 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestWriteKML(t *testing.T) {
+	c := qt.New(t)
+	var b bytes.Buffer
+	c.Assert(WriteKML(&b, fixturePlans(c)), qt.IsNil)
+	golden(c, "routes.kml", b.Bytes())
+
+	var doc kml
+	c.Assert(xml.Unmarshal(b.Bytes(), &doc), qt.IsNil)
+	c.Assert(doc.Document.Folders, qt.HasLen, 2)
+	c.Assert(doc.Document.Styles, qt.HasLen, 3)
+	c.Assert(doc.Document.Styles[1].LineStyle.Color, qt.Not(qt.Equals), doc.Document.Styles[2].LineStyle.Color)
+	for _, f := range doc.Document.Folders {
+		c.Assert(f.Placemarks[0].LineString, qt.Not(qt.IsNil))
+	}
+}
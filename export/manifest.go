@@ -0,0 +1,280 @@
+package export
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// Manifest is the data a manifest template is executed with.
+type Manifest struct {
+	Route   workwave.Route
+	Driver  *workwave.Driver  // nil if unknown
+	Vehicle *workwave.Vehicle // nil if unknown
+	// Departure and Arrival are the times the route leaves and returns to its
+	// depot, which are not set if the route has no such steps.
+	Departure workwave.SecOfDay
+	Arrival   workwave.SecOfDay
+	Stops     []ManifestStop
+}
+
+// ManifestStop is a step of the route which serves an order.
+type ManifestStop struct {
+	Number       int // from 1, in route order
+	DisplayLabel string
+	Type         workwave.StepType
+	// Name is the order name, or its ID if the order is unknown.
+	Name string
+	// ETA is the planned arrival, and Start and End the planned service.
+	ETA         workwave.SecOfDay
+	Start       workwave.SecOfDay
+	End         workwave.SecOfDay
+	TimeWindows []workwave.TimeWindow // on the route date
+	Address     string
+	Notes       string
+	ServiceTime time.Duration
+	Loads       []ManifestLoad // sorted by name
+}
+
+// ManifestLoad is a quantity of a load carried by an order.
+type ManifestLoad struct {
+	Name     string
+	Quantity int
+}
+
+// NewManifest prepares the manifest of a planned route.
+func NewManifest(p workwave.RoutePlan) *Manifest {
+	m := &Manifest{
+		Route:     p.Route,
+		Driver:    p.Driver,
+		Vehicle:   p.Vehicle,
+		Departure: -1,
+		Arrival:   -1,
+	}
+	for _, s := range p.Steps {
+		switch {
+		case s.Type == workwave.StepDeparture:
+			m.Departure = s.StartSec
+			if s.EndSec.IsSet() {
+				m.Departure = s.EndSec
+			}
+		case s.Type == workwave.StepArrival:
+			m.Arrival = s.ArrivalSec
+		case s.OrderID != "":
+			m.Stops = append(m.Stops, newManifestStop(len(m.Stops)+1, p.Route.Date, s))
+		}
+	}
+	return m
+}
+
+func newManifestStop(n int, d workwave.Date, s workwave.PlanStep) ManifestStop {
+	ms := ManifestStop{
+		Number:       n,
+		DisplayLabel: s.DisplayLabel,
+		Type:         s.Type,
+		Name:         s.OrderID,
+		ETA:          s.ArrivalSec,
+		Start:        s.StartSec,
+		End:          s.EndSec,
+	}
+	if s.Order != nil {
+		ms.Name = s.Order.Name
+		for _, k := range s.Order.Loads.Dimensions() {
+			ms.Loads = append(ms.Loads, ManifestLoad{Name: string(k), Quantity: s.Order.Loads[k]})
+		}
+	}
+	if s.OrderStep != nil {
+		ms.TimeWindows = s.OrderStep.TimeWindowsOn(d)
+		ms.Address = s.OrderStep.Location.Address
+		if ms.Address == "" && s.OrderStep.Location.LatLng != nil {
+			ms.Address = s.OrderStep.Location.LatLng.String()
+		}
+		ms.Notes = s.OrderStep.Notes
+		ms.ServiceTime = time.Duration(s.OrderStep.ServiceTimeSec) * time.Second
+	}
+	return ms
+}
+
+// ManifestFuncs are the functions available to manifest templates, in
+// addition to the builtin ones:
+//
+//	clock s       a time of day as HH:MM, or --:-- if it is not set
+//	pad n s       s truncated or padded with spaces to n characters
+//	wrap n s      the lines of s wrapped at n characters
+//	windows tws   time windows as HH:MM-HH:MM, separated by commas
+//	duration d    a duration in whole minutes, ie "1h05" or "10 min"
+//	loads ls      loads as "name quantity", separated by commas
+var ManifestFuncs = map[string]interface{}{
+	"clock":    clock,
+	"pad":      pad,
+	"wrap":     wrap,
+	"windows":  windowsString,
+	"duration": durationString,
+	"loads":    loadsString,
+}
+
+// ManifestText is the default template of WriteManifestText, which lays
+// manifests out for 80 column fixed-width printing.
+const ManifestText = `ROUTE MANIFEST {{.Route.Date}}
+Route:     {{.Route.ID}}
+{{with .Driver}}Driver:    {{.Name}}
+{{end}}{{with .Vehicle}}Vehicle:   {{.Name}}
+{{end}}Departure: {{clock .Departure}}
+Arrival:   {{clock .Arrival}}
+Stops:     {{len .Stops}}
+================================================================================
+#    ETA    ORDER
+--------------------------------------------------------------------------------
+{{range .Stops -}}
+{{pad 4 (print .Number)}} {{clock .ETA}}  {{if .DisplayLabel}}[{{.DisplayLabel}}] {{end}}{{.Name}} ({{.Type}})
+{{range wrap 68 .Address}}            {{.}}
+{{end -}}
+{{if .TimeWindows}}            Windows: {{windows .TimeWindows}}
+{{end -}}
+{{if .ServiceTime}}            Service: {{duration .ServiceTime}}
+{{end -}}
+{{if .Loads}}            Loads:   {{loads .Loads}}
+{{end -}}
+{{range $i, $line := wrap 59 .Notes}}            {{if $i}}         {{else}}Notes:   {{end}}{{$line}}
+{{end}}
+            Signature: ______________________________
+
+{{end -}}
+`
+
+// ManifestHTML is the default template of WriteManifestHTML, which renders
+// manifests as a standalone HTML page suited for printing.
+const ManifestHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Route manifest {{.Route.Date}}</title>
+<style>
+body { font-family: sans-serif; font-size: 11pt; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #999; padding: 4px; text-align: left; vertical-align: top; }
+tr { page-break-inside: avoid; }
+.signature { border-bottom: 1px solid #000; height: 2em; min-width: 12em; }
+</style>
+</head>
+<body>
+<h1>Route manifest {{.Route.Date}}</h1>
+<dl>
+<dt>Route</dt><dd>{{.Route.ID}}</dd>
+{{with .Driver}}<dt>Driver</dt><dd>{{.Name}}</dd>
+{{end}}{{with .Vehicle}}<dt>Vehicle</dt><dd>{{.Name}}</dd>
+{{end}}<dt>Departure</dt><dd>{{clock .Departure}}</dd>
+<dt>Arrival</dt><dd>{{clock .Arrival}}</dd>
+</dl>
+<table>
+<tr><th>#</th><th>ETA</th><th>Windows</th><th>Order</th><th>Address</th><th>Notes</th><th>Service</th><th>Loads</th><th>Signature</th></tr>
+{{range .Stops}}<tr>
+<td>{{.Number}}{{if .DisplayLabel}} ({{.DisplayLabel}}){{end}}</td>
+<td>{{clock .ETA}}</td>
+<td>{{windows .TimeWindows}}</td>
+<td>{{.Name}} ({{.Type}})</td>
+<td>{{.Address}}</td>
+<td>{{.Notes}}</td>
+<td>{{if .ServiceTime}}{{duration .ServiceTime}}{{end}}</td>
+<td>{{loads .Loads}}</td>
+<td><div class="signature"></div></td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+var (
+	defaultManifestText = texttemplate.Must(texttemplate.New("manifest").Funcs(ManifestFuncs).Parse(ManifestText))
+	defaultManifestHTML = htmltemplate.Must(htmltemplate.New("manifest").Funcs(ManifestFuncs).Parse(ManifestHTML))
+)
+
+// WriteManifestText writes the manifest of p as fixed-width text. If tmpl is
+// nil the ManifestText template is used, otherwise tmpl is executed with a
+// *Manifest, and may use ManifestFuncs if they were added to it.
+func WriteManifestText(w io.Writer, p workwave.RoutePlan, tmpl *texttemplate.Template) error {
+	if tmpl == nil {
+		tmpl = defaultManifestText
+	}
+	if err := tmpl.Execute(w, NewManifest(p)); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+	return nil
+}
+
+// WriteManifestHTML writes the manifest of p as HTML. If tmpl is nil the
+// ManifestHTML template is used, otherwise tmpl is executed with a *Manifest,
+// and may use ManifestFuncs if they were added to it.
+func WriteManifestHTML(w io.Writer, p workwave.RoutePlan, tmpl *htmltemplate.Template) error {
+	if tmpl == nil {
+		tmpl = defaultManifestHTML
+	}
+	if err := tmpl.Execute(w, NewManifest(p)); err != nil {
+		return errors.Wrap(err, "failed to write manifest")
+	}
+	return nil
+}
+
+func clock(s workwave.SecOfDay) string {
+	if !s.IsSet() {
+		return "--:--"
+	}
+	h, m, _ := s.Clock()
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+func pad(n int, s string) string {
+	if utf8.RuneCountInString(s) > n {
+		return string([]rune(s)[:n])
+	}
+	return s + strings.Repeat(" ", n-utf8.RuneCountInString(s))
+}
+
+func wrap(n int, s string) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > n:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func windowsString(tws []workwave.TimeWindow) string {
+	return strings.Join(timeWindows(tws), ", ")
+}
+
+func durationString(d time.Duration) string {
+	min := int(d.Round(time.Minute) / time.Minute)
+	if min < 60 {
+		return fmt.Sprintf("%d min", min)
+	}
+	return fmt.Sprintf("%dh%02d", min/60, min%60)
+}
+
+func loadsString(loads []ManifestLoad) string {
+	s := make([]string, len(loads))
+	for n, l := range loads {
+		s[n] = fmt.Sprintf("%s %d", l.Name, l.Quantity)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"testing"
+	texttemplate "text/template"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+func TestNewManifest(t *testing.T) {
+	c := qt.New(t)
+	plans := fixturePlans(c)
+
+	m := NewManifest(plans[0])
+	c.Assert(m.Driver.Name, qt.Equals, "Driver 2")
+	c.Assert(m.Vehicle.Name, qt.Equals, "Vehicle 1")
+	c.Assert(m.Departure, qt.Equals, workwave.SecOfDay(32329))
+	c.Assert(m.Arrival, qt.Equals, workwave.NewSecOfDay(13, 28, 0))
+	c.Assert(m.Stops, qt.HasLen, 2)
+	c.Assert(m.Stops[0], qt.DeepEquals, ManifestStop{
+		Number:       1,
+		DisplayLabel: "1.1",
+		Type:         workwave.StepDelivery,
+		Name:         "Order 1",
+		ETA:          32689,
+		Start:        32689,
+		End:          33289,
+		TimeWindows:  []workwave.TimeWindow{{StartSec: 30600, EndSec: 37800}, {StartSec: 45000, EndSec: 55800}},
+		Address:      "3101-3199 Florida Ave, Jasper, AL 35501, USA",
+		Notes:        "demonstrate the concept of multiple time windows as well as eligibility date range",
+		ServiceTime:  10 * time.Minute,
+	})
+
+	// Orders without an address are located by their coordinates.
+	m = NewManifest(plans[1])
+	c.Assert(m.Stops[0].Address, qt.Equals, "33.480873,-86.788220")
+	c.Assert(m.Stops[0].Loads, qt.DeepEquals, []ManifestLoad{{Name: "people", Quantity: 400}})
+
+	// Steps whose order is unknown are named by its ID.
+	m = NewManifest(workwave.RoutePlan{Steps: []workwave.PlanStep{
+		{RouteStep: workwave.RouteStep{Type: workwave.StepDelivery, OrderID: "deleted"}},
+	}})
+	c.Assert(m.Departure.IsSet(), qt.Equals, false)
+	c.Assert(m.Stops[0].Name, qt.Equals, "deleted")
+}
+
+func TestWriteManifestText(t *testing.T) {
+	c := qt.New(t)
+	for _, p := range fixturePlans(c) {
+		var b bytes.Buffer
+		c.Assert(WriteManifestText(&b, p, nil), qt.IsNil)
+		golden(c, p.Route.ID+".txt", b.Bytes())
+	}
+
+	tmpl := texttemplate.Must(texttemplate.New("custom").Funcs(ManifestFuncs).Parse(
+		`{{range .Stops}}{{.Number}} {{pad 8 .Name}}|{{clock .ETA}}{{"\n"}}{{end}}`))
+	var b bytes.Buffer
+	c.Assert(WriteManifestText(&b, fixturePlans(c)[0], tmpl), qt.IsNil)
+	c.Assert(b.String(), qt.Equals, "1 Order 1 |09:04\n2 Order 7 |12:17\n")
+
+	tmpl = texttemplate.Must(texttemplate.New("broken").Parse(`{{.Missing}}`))
+	err := WriteManifestText(&b, fixturePlans(c)[0], tmpl)
+	c.Assert(err, qt.ErrorMatches, "failed to write manifest: .*")
+}
+
+func TestWriteManifestHTML(t *testing.T) {
+	c := qt.New(t)
+	for _, p := range fixturePlans(c) {
+		var b bytes.Buffer
+		c.Assert(WriteManifestHTML(&b, p, nil), qt.IsNil)
+		golden(c, p.Route.ID+".html", b.Bytes())
+	}
+
+	// Order data is escaped.
+	p := fixturePlans(c)[0]
+	o := *p.Steps[1].Order
+	o.Name = "<b>Order 1</b>"
+	p.Steps[1].Order = &o
+	tmpl := htmltemplate.Must(htmltemplate.New("custom").Funcs(ManifestFuncs).Parse(
+		`{{range .Stops}}<p>{{.Name}} {{duration .ServiceTime}}</p>{{end}}`))
+	var b bytes.Buffer
+	c.Assert(WriteManifestHTML(&b, p, tmpl), qt.IsNil)
+	c.Assert(b.String(), qt.Equals, "<p>&lt;b&gt;Order 1&lt;/b&gt; 10 min</p><p>Order 7 30 min</p>")
+}
+
+func TestManifestFuncs(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(pad(5, "abc"), qt.Equals, "abc  ")
+	c.Assert(pad(2, "àbc"), qt.Equals, "àb")
+	c.Assert(wrap(10, "the quick brown fox\njumps"), qt.DeepEquals, []string{"the quick", "brown fox", "jumps"})
+	c.Assert(wrap(10, ""), qt.HasLen, 0)
+	c.Assert(durationString(25*time.Minute), qt.Equals, "25 min")
+	c.Assert(durationString(65*time.Minute), qt.Equals, "1h05")
+	c.Assert(clock(workwave.NewSecOfDay(9, 4, 49)), qt.Equals, "09:04")
+	c.Assert(clock(-1), qt.Equals, "--:--")
+	c.Assert(loadsString([]ManifestLoad{{"a", 1}, {"b", 2}}), qt.Equals, "a 1, b 2")
+}
@@ -0,0 +1,255 @@
+package workwave
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// jsonField is a field of a struct type decoded from a JSON object member.
+type jsonField struct {
+	name  string
+	index []int
+}
+
+// jsonFields caches the JSON fields of struct types.
+var jsonFields sync.Map // map[reflect.Type][]jsonField
+
+// fieldsOf returns the fields of struct type t which are encoded as JSON
+// object members, including the fields promoted from embedded structs which
+// are not shadowed.
+func fieldsOf(t reflect.Type) []jsonField {
+	if fields, ok := jsonFields.Load(t); ok {
+		return fields.([]jsonField)
+	}
+	fields := appendFields(nil, t, nil)
+	jsonFields.Store(t, fields)
+	return fields
+}
+
+func appendFields(fields []jsonField, t reflect.Type, index []int) []jsonField {
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, f)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields = append(fields, jsonField{name: name, index: append(index[:len(index):len(index)], i)})
+	}
+
+	n := len(fields)
+	for _, f := range embedded {
+		promoted := appendFields(nil, f.Type, append(index[:len(index):len(index)], f.Index...))
+	outer:
+		for _, p := range promoted {
+			for _, o := range fields[:n] {
+				if o.name == p.name {
+					continue outer
+				}
+			}
+			fields = append(fields, p)
+		}
+	}
+	return fields
+}
+
+// isField reports whether key is decoded into one of the fields of struct type
+// t, which like encoding/json is case-insensitive.
+func isField(t reflect.Type, key string) bool {
+	for _, f := range fieldsOf(t) {
+		if strings.EqualFold(f.name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// unmarshalObject decodes b into v, a pointer to a struct without JSON
+// methods, and the members of b which are not fields of v into extra. Members
+// are matched to fields like encoding/json does, preferring an exact match to
+// a case-insensitive one.
+func unmarshalObject(b []byte, v interface{}, extra *map[string]json.RawMessage) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	if members == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rv := reflect.ValueOf(v).Elem()
+	for _, f := range fieldsOf(rv.Type()) {
+		key, ok := f.name, false
+		if _, ok = members[key]; !ok {
+			for _, k := range keys {
+				if strings.EqualFold(k, f.name) {
+					key, ok = k, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(members[key], rv.FieldByIndex(f.index).Addr().Interface()); err != nil {
+			return err
+		}
+		delete(members, key)
+	}
+
+	if len(members) == 0 {
+		members = nil
+	}
+	*extra = members
+	return nil
+}
+
+// marshalObject encodes v, a struct without JSON methods, followed by the
+// members of extra which are not fields of v.
+func marshalObject(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	t := reflect.TypeOf(v)
+	keys := make([]string, 0, len(extra))
+	for k := range extra {
+		if !isField(t, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+	for i, k := range keys {
+		if i > 0 || len(b) > 2 {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		buf.Write(kb)
+		buf.WriteByte(':')
+		m := extra[k]
+		if len(m) == 0 {
+			m = json.RawMessage("null")
+		}
+		if !json.Valid(m) {
+			return nil, errors.Errorf("invalid JSON in extra field %q", k)
+		}
+		buf.Write(m)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+var rawMessageMap = reflect.TypeOf(map[string]json.RawMessage(nil))
+
+// checkExtra returns an error for the first value reachable from v whose Extra
+// field keeps unknown JSON fields.
+func checkExtra(v reflect.Value) error {
+	return walk(v, func(v reflect.Value) error {
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		f, ok := v.Type().FieldByName("Extra")
+		if !ok || f.Type != rawMessageMap {
+			return nil
+		}
+		extra := v.FieldByIndex(f.Index)
+		if extra.Len() == 0 {
+			return nil
+		}
+		keys := make([]string, 0, extra.Len())
+		for _, k := range extra.MapKeys() {
+			keys = append(keys, strconv.Quote(k.String()))
+		}
+		sort.Strings(keys)
+		return errors.Errorf("unknown fields in %s: %s", objectName(v.Type()), strings.Join(keys, ", "))
+	})
+}
+
+// walk calls visit with v and each value reachable from it through exported
+// fields, elements and map values, stopping at the first error. Map values are
+// visited in key order, so that the error does not depend on map iteration.
+func walk(v reflect.Value, visit func(reflect.Value) error) error {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	if err := visit(v); err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			return walk(v.Elem(), visit)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := walk(v.Field(i), visit); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := walk(v.Index(i), visit); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(a, b int) bool {
+			return fmt.Sprint(keys[a]) < fmt.Sprint(keys[b])
+		})
+		for _, k := range keys {
+			if err := walk(v.MapIndex(k), visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// objectName returns the name of struct type t in errors, such as "order step"
+// for OrderStep.
+func objectName(t reflect.Type) string {
+	var b strings.Builder
+	for i, r := range t.Name() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package workwave
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestExtraRoundTrip(t *testing.T) {
+	c := qt.New(t)
+
+	in := `{
+		"id": "o1",
+		"name": "Order 1",
+		"futureField": {"a": [1, 2]},
+		"pickup": {
+			"notes": "n",
+			"location": {"address": "a", "geocoder": "x"},
+			"barcodes": ["b1"]
+		},
+		"eligibility": {"type": "any", "window": 3}
+	}`
+	var o Order
+	c.Assert(json.Unmarshal([]byte(in), &o), qt.IsNil)
+	c.Assert(o.Name, qt.Equals, "Order 1")
+	c.Assert(o.Extra, qt.DeepEquals, map[string]json.RawMessage{
+		"futureField": json.RawMessage(`{"a": [1, 2]}`),
+	})
+	c.Assert(o.Pickup.Extra, qt.DeepEquals, map[string]json.RawMessage{
+		"barcodes": json.RawMessage(`["b1"]`),
+	})
+	c.Assert(o.Pickup.Location.Extra, qt.DeepEquals, map[string]json.RawMessage{
+		"geocoder": json.RawMessage(`"x"`),
+	})
+	c.Assert(o.Eligibility.Extra, qt.DeepEquals, map[string]json.RawMessage{
+		"window": json.RawMessage(`3`),
+	})
+
+	o.Name = "Renamed"
+	b, err := json.Marshal(o)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"id":"o1","name":"Renamed",`+
+		`"pickup":{"location":{"address":"a","geocoder":"x"},"notes":"n","barcodes":["b1"]},`+
+		`"eligibility":{"type":"any","window":3},"futureField":{"a":[1,2]}}`)
+
+	// Known fields take precedence over extra fields of the same name, and
+	// extra fields are added to otherwise empty objects.
+	b, err = json.Marshal(Route{
+		ID: "r1",
+		Extra: map[string]json.RawMessage{
+			"ID":      json.RawMessage(`"stale"`),
+			"planned": json.RawMessage(`true`),
+			"empty":   nil,
+		},
+		Steps: []RouteStep{{Extra: map[string]json.RawMessage{"x": json.RawMessage(`1`)}}},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"id":"r1","steps":[{"x":1}],"empty":null,"planned":true}`)
+
+	_, err = json.Marshal(Order{Extra: map[string]json.RawMessage{"x": json.RawMessage(`{`)}})
+	c.Assert(err, qt.ErrorMatches, `.*invalid JSON in extra field "x"`)
+
+	// Fields are matched case-insensitively, like encoding/json does.
+	var r Route
+	c.Assert(json.Unmarshal([]byte(`{"ID": "r1", "vehicleID": "v"}`), &r), qt.IsNil)
+	c.Assert(r, qt.DeepEquals, Route{ID: "r1", VehicleID: "v"})
+}
+
+func TestStrictDecoding(t *testing.T) {
+	t.Parallel()
+	c := qt.New(t)
+	strict := &Client{strictDecoding: true}
+
+	var o Order
+	c.Assert(json.Unmarshal([]byte(`{"id": "o1", "pickup": {"notes": "n", "barcodes": []}}`), &o), qt.IsNil)
+	c.Assert(strict.checkDecoded(&o), qt.ErrorMatches, `unknown fields in order step: "barcodes"`)
+	c.Assert((&Client{}).checkDecoded(&o), qt.IsNil)
+
+	var r Route
+	c.Assert(json.Unmarshal([]byte(`{"id": "r1", "steps": [{"type": "arrival", "trackingData": {"eta": 1}}]}`), &r), qt.IsNil)
+	c.Assert(strict.checkDecoded(&r), qt.ErrorMatches, `unknown fields in tracking data: "eta"`)
+
+	// Unknown fields of time windows are dropped.
+	var s OrderStep
+	c.Assert(json.Unmarshal([]byte(`{"timeWindows": [{"startSec": 1, "x": 2}]}`), &s), qt.IsNil)
+	c.Assert(strict.checkDecoded(&s), qt.IsNil)
+}
+
+func TestStrictDecodingClient(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join("testdata", "routes-list-approved.json"))
+	})
+
+	// Fields of the fixture which are not modelled.
+	client, _ := New("api-key", WithStrictDecoding())
+	client.baseURL, _ = url.Parse(server.URL)
+	input := RoutesListApprovedInput{TerritoryID: "territory", Date: "20191019"}
+	_, err := client.Routes.ListApproved(ctx, input)
+	c.Assert(err, qt.ErrorMatches, `failed to decode JSON: unknown fields in route step: "distanceToNextMt", "driveToNextSec", "idleTimeSec", "perStopTimeSec"`)
+
+	client, _ = New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+	routes, err := client.Routes.(RoutesMapLister).ListApprovedMap(ctx, input)
+	c.Assert(err, qt.IsNil)
+	step := routes["0d8855e6-28a0-4e89-9c67-b44c66c39ba6-20151204"].Steps[1]
+	c.Assert(string(step.Extra["driveToNextSec"]), qt.Equals, "3232")
+	c.Assert(string(step.Extra["distanceToNextMt"]), qt.Equals, "72814")
+}
@@ -0,0 +1,178 @@
+package workwave
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	gpsBasePath         = "/api/v1/territories/%s/gps"
+	gpsDevicesPath      = gpsBasePath + "/devices"
+	gpsDeviceAssignPath = gpsDevicesPath + "/%s/vehicle"
+	gpsPositionsPath    = gpsBasePath + "/positions"
+	gpsHistoryPath      = gpsBasePath + "/vehicles/%s/history"
+)
+
+// GPSService is an interface to GPS devices and vehicle positions in the
+// WorkWave API.
+type GPSService interface {
+	ListDevices(context.Context, GPSListDevicesInput) ([]GPSDevice, error)
+	AssignDevice(context.Context, GPSAssignDeviceInput) error
+	LatestPositions(context.Context, GPSLatestPositionsInput) ([]Position, error)
+	History(context.Context, GPSHistoryInput) ([]Position, error)
+}
+
+type gpsService struct {
+	client *Client
+}
+
+// GPSDevice represents a GPS tracking device in the WorkWave API.
+// A device reports positions for the vehicle it is associated with.
+type GPSDevice struct {
+	ID        string `json:"id,omitempty"`
+	Label     string `json:"label,omitempty"`
+	VehicleID string `json:"vehicleId,omitempty"`
+}
+
+// Position is a single GPS sample reported by a device.
+// Speed and Heading are only present when reported by the device.
+type Position struct {
+	DeviceID  string   `json:"deviceId,omitempty"`
+	VehicleID string   `json:"vehicleId,omitempty"`
+	LatLng    LatLng   `json:"latLng"`
+	TS        int64    `json:"ts"`                // seconds since the Unix epoch
+	Speed     *float64 `json:"speed,omitempty"`   // km/h
+	Heading   *float64 `json:"heading,omitempty"` // degrees clockwise from north
+}
+
+// Time returns the time at which the position was sampled.
+func (p Position) Time() time.Time {
+	return time.Unix(p.TS, 0)
+}
+
+type gpsDevicesResponse struct {
+	Devices []GPSDevice `json:"devices"`
+}
+
+type gpsPositionsResponse struct {
+	Positions []Position `json:"positions"`
+}
+
+// GPSListDevicesInput is used to populate a call to List GPS Devices on the
+// WorkWave API.
+type GPSListDevicesInput struct {
+	TerritoryID string
+}
+
+// ListDevices lists the GPS devices available in a territory.
+func (svc *gpsService) ListDevices(ctx context.Context, i GPSListDevicesInput) ([]GPSDevice, error) {
+	u := fmt.Sprintf(gpsDevicesPath, i.TerritoryID)
+	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gps devices list request")
+	}
+
+	dr := &gpsDevicesResponse{}
+	if _, err := svc.client.Do(ctx, req, dr); err != nil {
+		return nil, err
+	}
+	return dr.Devices, nil
+}
+
+// GPSAssignDeviceInput is used to populate a call to Assign GPS Device on the
+// WorkWave API.
+type GPSAssignDeviceInput struct {
+	TerritoryID string `json:"-"`
+	DeviceID    string `json:"-"`
+	// VehicleID is the vehicle to associate the device with. An empty
+	// VehicleID removes any existing association.
+	VehicleID string `json:"vehicleId"`
+}
+
+// AssignDevice associates a GPS device with a vehicle.
+func (svc *gpsService) AssignDevice(ctx context.Context, i GPSAssignDeviceInput) error {
+	u := fmt.Sprintf(gpsDeviceAssignPath, i.TerritoryID, i.DeviceID)
+	req, err := svc.client.NewRequest(ctx, http.MethodPost, u, i)
+	if err != nil {
+		return errors.Wrap(err, "failed to create gps device assign request")
+	}
+
+	_, err = svc.client.Do(ctx, req, nil)
+	return err
+}
+
+// GPSLatestPositionsInput is used to populate a call to Latest Positions on
+// the WorkWave API.
+type GPSLatestPositionsInput struct {
+	TerritoryID string
+	// VehicleIDs optionally restricts the results to the given vehicles.
+	VehicleIDs []string
+}
+
+// LatestPositions retrieves the most recent position of each tracked vehicle.
+func (svc *gpsService) LatestPositions(ctx context.Context, i GPSLatestPositionsInput) ([]Position, error) {
+	u := fmt.Sprintf(gpsPositionsPath, i.TerritoryID)
+	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gps latest positions request")
+	}
+
+	// Query params
+	q := req.URL.Query()
+	for _, id := range i.VehicleIDs {
+		q.Add("vehicle", id)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	pr := &gpsPositionsResponse{}
+	if _, err := svc.client.Do(ctx, req, pr); err != nil {
+		return nil, err
+	}
+	return pr.Positions, nil
+}
+
+// GPSHistoryInput is used to populate a call to Position History on the
+// WorkWave API.
+type GPSHistoryInput struct {
+	TerritoryID string
+	VehicleID   string
+	From        time.Time
+	To          time.Time
+}
+
+// History retrieves the breadcrumb track of a vehicle between From and To.
+// Positions are returned in chronological order.
+func (svc *gpsService) History(ctx context.Context, i GPSHistoryInput) ([]Position, error) {
+	if i.To.Before(i.From) {
+		return nil, errors.New("gps history range ends before it starts")
+	}
+
+	u := fmt.Sprintf(gpsHistoryPath, i.TerritoryID, i.VehicleID)
+	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create gps history request")
+	}
+
+	// Query params
+	q := req.URL.Query()
+	q.Add("from", strconv.FormatInt(i.From.Unix(), 10))
+	q.Add("to", strconv.FormatInt(i.To.Unix(), 10))
+	req.URL.RawQuery = q.Encode()
+
+	pr := &gpsPositionsResponse{}
+	if _, err := svc.client.Do(ctx, req, pr); err != nil {
+		return nil, err
+	}
+
+	positions := pr.Positions
+	sort.SliceStable(positions, func(a, b int) bool {
+		return positions[a].TS < positions[b].TS
+	})
+	return positions, nil
+}
@@ -0,0 +1,137 @@
+package workwave
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestGPSListDevices(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/api/v1/territories/territory/gps/devices", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"devices": [
+			{"id": "dev-1", "label": "Truck 1 tracker", "vehicleId": "vehicle-1"},
+			{"id": "dev-2", "label": "Spare"}
+		]}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	d, err := client.GPS.ListDevices(ctx, GPSListDevicesInput{TerritoryID: "territory"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(d, qt.DeepEquals, []GPSDevice{
+		{ID: "dev-1", Label: "Truck 1 tracker", VehicleID: "vehicle-1"},
+		{ID: "dev-2", Label: "Spare"},
+	})
+}
+
+func TestGPSAssignDevice(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	var got GPSAssignDeviceInput
+	mux.HandleFunc("/api/v1/territories/territory/gps/devices/dev-1/vehicle", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, qt.Equals, http.MethodPost)
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal("failed to parse request body")
+		}
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	err := client.GPS.AssignDevice(ctx, GPSAssignDeviceInput{
+		TerritoryID: "territory",
+		DeviceID:    "dev-1",
+		VehicleID:   "vehicle-1",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(got.VehicleID, qt.Equals, "vehicle-1")
+}
+
+func TestGPSLatestPositions(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/api/v1/territories/territory/gps/positions", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.Query()["vehicle"], qt.DeepEquals, []string{"vehicle-1", "vehicle-2"})
+		fmt.Fprint(w, `{"positions": [
+			{"deviceId": "dev-1", "vehicleId": "vehicle-1", "latLng": [33817872, -87266893], "ts": 1449219600, "speed": 42.5, "heading": 90},
+			{"deviceId": "dev-2", "vehicleId": "vehicle-2", "latLng": [33845214, -87273604], "ts": 1449219610}
+		]}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	p, err := client.GPS.LatestPositions(ctx, GPSLatestPositionsInput{
+		TerritoryID: "territory",
+		VehicleIDs:  []string{"vehicle-1", "vehicle-2"},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(p), qt.Equals, 2)
+	c.Assert(*p[0].Speed, qt.Equals, 42.5)
+	c.Assert(*p[0].Heading, qt.Equals, 90.0)
+	c.Assert(p[0].Time().Equal(time.Unix(1449219600, 0)), qt.Equals, true)
+	c.Assert(p[1].Speed, qt.IsNil)
+	c.Assert(p[1].Heading, qt.IsNil)
+}
+
+func TestGPSHistory(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/api/v1/territories/territory/gps/vehicles/vehicle-1/history", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("from") != "1449216000" || q.Get("to") != "1449259200" {
+			http.Error(w, "bad range", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"positions": [
+			{"vehicleId": "vehicle-1", "latLng": [33480873, -86788220], "ts": 1449219700},
+			{"vehicleId": "vehicle-1", "latLng": [33817872, -87266893], "ts": 1449219600}
+		]}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	from := time.Unix(1449216000, 0)
+	to := time.Unix(1449259200, 0)
+
+	t.Run("sorted by time", func(t *testing.T) {
+		c := qt.New(t)
+		p, err := client.GPS.History(ctx, GPSHistoryInput{
+			TerritoryID: "territory",
+			VehicleID:   "vehicle-1",
+			From:        from,
+			To:          to,
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(p), qt.Equals, 2)
+		c.Assert(p[0].TS, qt.Equals, int64(1449219600))
+		c.Assert(p[1].TS, qt.Equals, int64(1449219700))
+	})
+
+	t.Run("inverted range", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := client.GPS.History(ctx, GPSHistoryInput{
+			TerritoryID: "territory",
+			VehicleID:   "vehicle-1",
+			From:        to,
+			To:          from,
+		})
+		c.Assert(err, qt.ErrorMatches, "gps history range ends before it starts")
+	})
+}
@@ -0,0 +1,79 @@
+// Package fixture loads the API responses in the testdata directory at the
+// root of the repository, which are shared by the tests of its packages.
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// Response is a list response of the API, keyed by ID. Routes, drivers and
+// vehicles are only set for route lists.
+type Response struct {
+	Routes   map[string]workwave.Route   `json:"routes"`
+	Orders   map[string]workwave.Order   `json:"orders"`
+	Drivers  map[string]workwave.Driver  `json:"drivers"`
+	Vehicles map[string]workwave.Vehicle `json:"vehicles"`
+}
+
+// Load decodes the named response of the testdata directory.
+func Load(c *qt.C, name string) Response {
+	_, file, _, _ := runtime.Caller(0)
+	b, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "..", "..", "testdata", name))
+	c.Assert(err, qt.IsNil)
+	var r Response
+	c.Assert(json.Unmarshal(b, &r), qt.IsNil)
+	return r
+}
+
+// SortedRoutes returns the routes of r sorted by ID.
+func (r Response) SortedRoutes() []workwave.Route {
+	var routes []workwave.Route
+	for _, route := range r.Routes {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(a, b int) bool { return routes[a].ID < routes[b].ID })
+	return routes
+}
+
+// SortedOrders returns the orders of r sorted by name.
+func (r Response) SortedOrders() []workwave.Order {
+	var orders []workwave.Order
+	for _, o := range r.Orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(a, b int) bool { return orders[a].Name < orders[b].Name })
+	return orders
+}
+
+// Plans returns the routes of r, sorted by ID and joined with their orders,
+// drivers and vehicles.
+func (r Response) Plans(c *qt.C) []workwave.RoutePlan {
+	i := workwave.PlanRoutesInput{Routes: r.SortedRoutes()}
+	for _, d := range r.Drivers {
+		i.Drivers = append(i.Drivers, d)
+	}
+	for _, v := range r.Vehicles {
+		i.Vehicles = append(i.Vehicles, v)
+	}
+	plans, err := workwave.PlanRoutes(context.Background(), orders{orders: r.SortedOrders()}, i)
+	c.Assert(err, qt.IsNil)
+	return plans
+}
+
+// orders answers every call to Get with the orders of a response.
+type orders struct {
+	workwave.OrdersService
+	orders []workwave.Order
+}
+
+func (o orders) Get(ctx context.Context, i workwave.OrdersGetInput) ([]workwave.Order, error) {
+	return o.orders, nil
+}
@@ -0,0 +1,160 @@
+package workwave
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	microDegrees = 1e6
+	// earthRadius is the mean radius of the Earth in meters.
+	earthRadius = 6371008.8
+)
+
+// LatLng is a coordinate expressed in micro-degrees of latitude and longitude,
+// as used by the WorkWave API. ie, {33817872, -87266893} is 33.817872 degrees
+// latitude and -87.266893 degrees longitude.
+type LatLng [2]int
+
+// NewLatLng returns the LatLng for the given latitude and longitude in
+// degrees, rounded to the nearest micro-degree.
+func NewLatLng(lat, lng float64) LatLng {
+	return LatLng{
+		int(math.Round(lat * microDegrees)),
+		int(math.Round(lng * microDegrees)),
+	}
+}
+
+// Lat returns the latitude of ll in degrees.
+func (ll LatLng) Lat() float64 {
+	return float64(ll[0]) / microDegrees
+}
+
+// Lng returns the longitude of ll in degrees.
+func (ll LatLng) Lng() float64 {
+	return float64(ll[1]) / microDegrees
+}
+
+// Valid reports whether ll is within the range of valid latitudes and
+// longitudes.
+func (ll LatLng) Valid() bool {
+	return ll[0] >= -90*microDegrees && ll[0] <= 90*microDegrees &&
+		ll[1] >= -180*microDegrees && ll[1] <= 180*microDegrees
+}
+
+// String returns ll in degrees, ie "33.817872,-87.266893".
+func (ll LatLng) String() string {
+	return fmt.Sprintf("%.6f,%.6f", ll.Lat(), ll.Lng())
+}
+
+// DistanceTo returns the great-circle distance in meters between ll and o,
+// using the haversine formula.
+func (ll LatLng) DistanceTo(o LatLng) float64 {
+	lat1, lat2 := radians(ll.Lat()), radians(o.Lat())
+	dLat := lat2 - lat1
+	dLng := radians(o.Lng() - ll.Lng())
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// BearingTo returns the initial bearing in degrees clockwise from north, in
+// the range [0, 360), of the great-circle path from ll to o.
+func (ll LatLng) BearingTo(o LatLng) float64 {
+	lat1, lat2 := radians(ll.Lat()), radians(o.Lat())
+	dLng := radians(o.Lng() - ll.Lng())
+
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It requires exactly two
+// coordinates, and leaves ll unchanged for null.
+func (ll *LatLng) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var v []int
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errors.Wrap(err, "failed to decode latLng")
+	}
+	if len(v) != 2 {
+		return errors.Errorf("invalid latLng: expected 2 coordinates, got %d", len(v))
+	}
+	*ll = LatLng{v[0], v[1]}
+	return nil
+}
+
+// BoundingBox is a rectangular area between a south-west and a north-east
+// corner. Bounding boxes crossing the antimeridian are not supported.
+type BoundingBox struct {
+	SouthWest LatLng
+	NorthEast LatLng
+}
+
+// BoundsOf returns the smallest BoundingBox containing all of the given
+// points. It returns the zero BoundingBox when no points are given.
+func BoundsOf(points ...LatLng) BoundingBox {
+	if len(points) == 0 {
+		return BoundingBox{}
+	}
+	b := BoundingBox{SouthWest: points[0], NorthEast: points[0]}
+	for _, p := range points[1:] {
+		b = b.Extend(p)
+	}
+	return b
+}
+
+// BoundsAround returns the BoundingBox containing every point within radius
+// meters of center.
+func BoundsAround(center LatLng, radius float64) BoundingBox {
+	dLat := degrees(radius / earthRadius)
+	dLng := 180.0
+	if c := math.Cos(radians(center.Lat())); c > 1e-9 {
+		dLng = math.Min(180, dLat/c)
+	}
+	return BoundingBox{
+		SouthWest: NewLatLng(math.Max(-90, center.Lat()-dLat), math.Max(-180, center.Lng()-dLng)),
+		NorthEast: NewLatLng(math.Min(90, center.Lat()+dLat), math.Min(180, center.Lng()+dLng)),
+	}
+}
+
+// Contains reports whether ll is inside b, including its edges.
+func (b BoundingBox) Contains(ll LatLng) bool {
+	return ll[0] >= b.SouthWest[0] && ll[0] <= b.NorthEast[0] &&
+		ll[1] >= b.SouthWest[1] && ll[1] <= b.NorthEast[1]
+}
+
+// Extend returns the smallest BoundingBox containing both b and ll.
+func (b BoundingBox) Extend(ll LatLng) BoundingBox {
+	for i := range ll {
+		if ll[i] < b.SouthWest[i] {
+			b.SouthWest[i] = ll[i]
+		}
+		if ll[i] > b.NorthEast[i] {
+			b.NorthEast[i] = ll[i]
+		}
+	}
+	return b
+}
+
+// Center returns the point halfway between the corners of b.
+func (b BoundingBox) Center() LatLng {
+	return LatLng{
+		(b.SouthWest[0] + b.NorthEast[0]) / 2,
+		(b.SouthWest[1] + b.NorthEast[1]) / 2,
+	}
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
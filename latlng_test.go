@@ -0,0 +1,113 @@
+package workwave
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var (
+	london = NewLatLng(51.5074, -0.1278)
+	paris  = NewLatLng(48.8566, 2.3522)
+)
+
+func approx(c *qt.C, got, want, tolerance float64) {
+	c.Helper()
+	if math.Abs(got-want) > tolerance {
+		c.Fatalf("got %f, want %f ± %f", got, want, tolerance)
+	}
+}
+
+func TestLatLngDegrees(t *testing.T) {
+	c := qt.New(t)
+	ll := NewLatLng(33.817872, -87.266893)
+
+	c.Assert(ll, qt.Equals, LatLng{33817872, -87266893})
+	c.Assert(ll.Lat(), qt.Equals, 33.817872)
+	c.Assert(ll.Lng(), qt.Equals, -87.266893)
+	c.Assert(ll.String(), qt.Equals, "33.817872,-87.266893")
+	c.Assert(NewLatLng(-0.0000004, 0.0000006), qt.Equals, LatLng{0, 1})
+}
+
+func TestLatLngValid(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		ll   LatLng
+		want bool
+	}{
+		{name: "origin", ll: LatLng{}, want: true},
+		{name: "corner", ll: LatLng{-90000000, 180000000}, want: true},
+		{name: "latitude too high", ll: LatLng{90000001, 0}, want: false},
+		{name: "longitude too low", ll: LatLng{0, -180000001}, want: false},
+		{name: "micro-degrees mistaken for degrees", ll: NewLatLng(33817872, -87266893), want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.ll.Valid(), qt.Equals, tt.want)
+		})
+	}
+}
+
+func TestLatLngDistanceAndBearing(t *testing.T) {
+	c := qt.New(t)
+
+	approx(c, london.DistanceTo(paris), 343556, 1)
+	approx(c, paris.DistanceTo(london), 343556, 1)
+	approx(c, london.DistanceTo(london), 0, 0)
+	approx(c, LatLng{33817872, -87266893}.DistanceTo(LatLng{33480873, -86788220}), 58029, 1)
+
+	approx(c, london.BearingTo(paris), 148.116, 0.001)
+	approx(c, paris.BearingTo(london), 330.021, 0.001)
+	approx(c, NewLatLng(0, 0).BearingTo(NewLatLng(0, -1)), 270, 1e-9)
+}
+
+func TestBoundingBox(t *testing.T) {
+	c := qt.New(t)
+
+	b := BoundsOf(london, paris, NewLatLng(50, 1))
+	c.Assert(b, qt.Equals, BoundingBox{
+		SouthWest: NewLatLng(48.8566, -0.1278),
+		NorthEast: NewLatLng(51.5074, 2.3522),
+	})
+	c.Assert(b.Contains(NewLatLng(50, 1)), qt.Equals, true)
+	c.Assert(b.Contains(london), qt.Equals, true)
+	c.Assert(b.Contains(NewLatLng(52, 1)), qt.Equals, false)
+	c.Assert(b.Center(), qt.Equals, NewLatLng(50.182, 1.1122))
+	c.Assert(BoundsOf(), qt.Equals, BoundingBox{})
+
+	around := BoundsAround(paris, 10000)
+	c.Assert(around.Contains(paris), qt.Equals, true)
+	approx(c, paris.DistanceTo(LatLng{around.NorthEast[0], paris[1]}), 10000, 1)
+	approx(c, paris.DistanceTo(LatLng{paris[0], around.SouthWest[1]}), 10000, 50)
+	c.Assert(around.Contains(london), qt.Equals, false)
+
+	pole := BoundsAround(NewLatLng(90, 0), 1000)
+	c.Assert(pole.NorthEast.Lat(), qt.Equals, 90.0)
+	c.Assert(pole.SouthWest.Lng(), qt.Equals, -180.0)
+}
+
+func TestLatLngJSON(t *testing.T) {
+	c := qt.New(t)
+
+	var l Location
+	err := json.Unmarshal([]byte(`{"address": "Jasper", "latLng": [33817872, -87266893]}`), &l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(*l.LatLng, qt.Equals, LatLng{33817872, -87266893})
+
+	b, err := json.Marshal(l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"address":"Jasper","latLng":[33817872,-87266893]}`)
+
+	l = Location{}
+	err = json.Unmarshal([]byte(`{"latLng": null}`), &l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(l.LatLng, qt.IsNil)
+
+	err = json.Unmarshal([]byte(`{"latLng": [1, 2, 3]}`), &l)
+	c.Assert(err, qt.ErrorMatches, "invalid latLng: expected 2 coordinates, got 3")
+
+	err = json.Unmarshal([]byte(`{"latLng": [33.8, -87.2]}`), &l)
+	c.Assert(err, qt.ErrorMatches, "failed to decode latLng.*")
+}
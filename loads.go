@@ -0,0 +1,201 @@
+package workwave
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LoadDimension is the name of a kind of load, such as "weight" or "pallets".
+// The dimensions of a territory are configured in WorkWave.
+type LoadDimension string
+
+// Loads are quantities by dimension, such as the loads carried by an order or
+// the capacities of a vehicle.
+type Loads map[LoadDimension]int
+
+// Dimensions returns the dimensions of l, sorted.
+func (l Loads) Dimensions() []LoadDimension {
+	dims := make([]LoadDimension, 0, len(l))
+	for d := range l {
+		dims = append(dims, d)
+	}
+	sort.Slice(dims, func(a, b int) bool { return dims[a] < dims[b] })
+	return dims
+}
+
+// Plus returns the sum of l and o.
+func (l Loads) Plus(o Loads) Loads {
+	sum := make(Loads, len(l))
+	for d, n := range l {
+		sum[d] = n
+	}
+	for d, n := range o {
+		sum[d] += n
+	}
+	return sum
+}
+
+// Minus returns the difference of l and o.
+func (l Loads) Minus(o Loads) Loads {
+	diff := make(Loads, len(l))
+	for d, n := range l {
+		diff[d] = n
+	}
+	for d, n := range o {
+		diff[d] -= n
+	}
+	return diff
+}
+
+// Exceeds returns the dimensions, sorted, in which l is more than capacity.
+// Dimensions without a capacity are unlimited.
+func (l Loads) Exceeds(capacity Loads) []LoadDimension {
+	var dims []LoadDimension
+	for _, d := range l.Dimensions() {
+		if c, ok := capacity[d]; ok && l[d] > c {
+			dims = append(dims, d)
+		}
+	}
+	return dims
+}
+
+// StepLoad is the load of a vehicle when leaving a route step.
+type StepLoad struct {
+	Index int // of the step in the route
+	RouteStep
+	Load Loads
+}
+
+// stepLoadJSON is a StepLoad without the JSON methods promoted from RouteStep,
+// which would encode the route step alone.
+type stepLoadJSON struct {
+	Index int
+	routeStepJSON
+	Load Loads
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l StepLoad) MarshalJSON() ([]byte, error) {
+	return marshalObject(stepLoadJSON{l.Index, routeStepJSON(l.RouteStep), l.Load}, l.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *StepLoad) UnmarshalJSON(b []byte) error {
+	var v stepLoadJSON
+	if err := unmarshalObject(b, &v, &v.Extra); err != nil {
+		return err
+	}
+	*l = StepLoad{v.Index, RouteStep(v.routeStepJSON), v.Load}
+	return nil
+}
+
+// CapacityViolation is a route step after which the vehicle is loaded beyond
+// its capacity in a dimension.
+type CapacityViolation struct {
+	Index     int // of the step in the route
+	Type      StepType
+	OrderID   string
+	Dimension LoadDimension
+	Load      int
+	Capacity  int
+}
+
+func (v CapacityViolation) Error() string {
+	return fmt.Sprintf("step %d (%s %s): %s load %d exceeds capacity %d",
+		v.Index, v.Type, v.OrderID, v.Dimension, v.Load, v.Capacity)
+}
+
+// LoadReport is the outcome of CheckLoads.
+type LoadReport struct {
+	// Initial is the load of the vehicle at departure: the loads of the
+	// orders delivered by the route without being picked up by it.
+	Initial Loads
+	// Steps has an entry for each of the route's steps, in the same order.
+	Steps      []StepLoad
+	Violations []CapacityViolation
+	// MissingOrders are the IDs of orders served by the route which were not
+	// given, and whose loads are not included.
+	MissingOrders []string
+}
+
+// OK reports whether the route stays within capacity.
+func (r *LoadReport) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// CheckLoads computes the load of a vehicle along route r, given the orders
+// it serves and the capacities of its vehicle. Orders delivered by the route
+// but not picked up by it are loaded at departure, pickups add the loads of
+// their order and deliveries remove them.
+func CheckLoads(r Route, orders []Order, capacities Loads) *LoadReport {
+	byID := make(map[string]*Order, len(orders))
+	for i := range orders {
+		byID[orders[i].ID] = &orders[i]
+	}
+
+	report := &LoadReport{Initial: Loads{}}
+	missing := make(map[string]bool)
+	pickedUp := make(map[string]bool)
+	for _, s := range r.Steps {
+		if s.Type == StepPickup {
+			pickedUp[s.OrderID] = true
+		}
+	}
+	for _, s := range r.Steps {
+		if s.Type != StepDelivery || pickedUp[s.OrderID] {
+			continue
+		}
+		if o := byID[s.OrderID]; o != nil {
+			report.Initial = report.Initial.Plus(o.Loads)
+		}
+	}
+
+	load := report.Initial
+	for i, s := range r.Steps {
+		if s.Type == StepPickup || s.Type == StepDelivery {
+			o := byID[s.OrderID]
+			switch {
+			case o == nil:
+				if !missing[s.OrderID] {
+					missing[s.OrderID] = true
+					report.MissingOrders = append(report.MissingOrders, s.OrderID)
+				}
+			case s.Type == StepPickup:
+				load = load.Plus(o.Loads)
+			default:
+				load = load.Minus(o.Loads)
+			}
+		}
+
+		report.Steps = append(report.Steps, StepLoad{Index: i, RouteStep: s, Load: load})
+		for _, d := range load.Exceeds(capacities) {
+			report.Violations = append(report.Violations, CapacityViolation{
+				Index:     i,
+				Type:      s.Type,
+				OrderID:   s.OrderID,
+				Dimension: d,
+				Load:      load[d],
+				Capacity:  capacities[d],
+			})
+		}
+	}
+	return report
+}
+
+// CheckLoads checks the loads of the plan's route against the capacities of
+// its vehicle, which are unlimited if the plan has no vehicle.
+func (p RoutePlan) CheckLoads() *LoadReport {
+	var orders []Order
+	seen := make(map[string]bool)
+	for _, s := range p.Steps {
+		if s.Order != nil && !seen[s.Order.ID] {
+			seen[s.Order.ID] = true
+			orders = append(orders, *s.Order)
+		}
+	}
+	var capacities Loads
+	if p.Vehicle != nil {
+		capacities = p.Vehicle.Capacities
+	}
+	return CheckLoads(p.Route, orders, capacities)
+}
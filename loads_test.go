@@ -0,0 +1,100 @@
+package workwave
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLoads(t *testing.T) {
+	c := qt.New(t)
+	a := Loads{"weight": 10, "pallets": 2}
+	b := Loads{"weight": 4, "volume": 3}
+
+	c.Assert(a.Plus(b), qt.DeepEquals, Loads{"weight": 14, "pallets": 2, "volume": 3})
+	c.Assert(a.Minus(b), qt.DeepEquals, Loads{"weight": 6, "pallets": 2, "volume": -3})
+	c.Assert(a, qt.DeepEquals, Loads{"weight": 10, "pallets": 2})
+	c.Assert(a.Dimensions(), qt.DeepEquals, []LoadDimension{"pallets", "weight"})
+
+	c.Assert(a.Exceeds(Loads{"weight": 10, "pallets": 1}), qt.DeepEquals, []LoadDimension{"pallets"})
+	c.Assert(a.Exceeds(Loads{"volume": 0}), qt.IsNil)
+	c.Assert(a.Exceeds(nil), qt.IsNil)
+}
+
+func TestCheckLoads(t *testing.T) {
+	c := qt.New(t)
+	orders := []Order{
+		{ID: "d1", Loads: Loads{"weight": 300}, Delivery: &OrderStep{}},
+		{ID: "d2", Loads: Loads{"weight": 200, "pallets": 1}, Delivery: &OrderStep{}},
+		{ID: "pd", Loads: Loads{"weight": 600}, Pickup: &OrderStep{}, Delivery: &OrderStep{}},
+		{ID: "p", Loads: Loads{"weight": 100}, Pickup: &OrderStep{}},
+	}
+	r := Route{
+		ID: "r1",
+		Steps: []RouteStep{
+			{Type: StepDeparture},
+			{Type: StepDelivery, OrderID: "d1"},
+			{Type: StepPickup, OrderID: "pd"},
+			{Type: StepPickup, OrderID: "p"},
+			{Type: StepDelivery, OrderID: "gone"},
+			{Type: StepDelivery, OrderID: "pd"},
+			{Type: StepDelivery, OrderID: "d2"},
+			{Type: StepArrival},
+		},
+	}
+
+	report := CheckLoads(r, orders, Loads{"weight": 900, "pallets": 2})
+	c.Assert(report.Initial, qt.DeepEquals, Loads{"weight": 500, "pallets": 1})
+	var weights []int
+	for i, s := range report.Steps {
+		c.Check(s.Index, qt.Equals, i)
+		c.Check(s.RouteStep, qt.DeepEquals, r.Steps[i])
+		weights = append(weights, s.Load["weight"])
+	}
+	c.Assert(weights, qt.DeepEquals, []int{500, 200, 800, 900, 900, 300, 100, 100})
+	c.Assert(report.OK(), qt.Equals, true)
+	c.Assert(report.MissingOrders, qt.DeepEquals, []string{"gone"})
+
+	report = CheckLoads(r, orders, Loads{"weight": 850})
+	c.Assert(report.OK(), qt.Equals, false)
+	c.Assert(report.Violations, qt.DeepEquals, []CapacityViolation{
+		{Index: 3, Type: StepPickup, OrderID: "p", Dimension: "weight", Load: 900, Capacity: 850},
+		{Index: 4, Type: StepDelivery, OrderID: "gone", Dimension: "weight", Load: 900, Capacity: 850},
+	})
+	c.Assert(report.Violations[0].Error(), qt.Equals, "step 3 (pickup p): weight load 900 exceeds capacity 850")
+
+	// Plans check against their vehicle.
+	p := RoutePlan{
+		Route:   r,
+		Vehicle: &Vehicle{ID: "v1", Capacities: Loads{"pallets": 0}},
+	}
+	for _, s := range r.Steps {
+		ps := PlanStep{RouteStep: s}
+		for j := range orders {
+			if orders[j].ID == s.OrderID {
+				ps.Order = &orders[j]
+			}
+		}
+		p.Steps = append(p.Steps, ps)
+	}
+	report = p.CheckLoads()
+	c.Assert(report.Violations, qt.HasLen, 6)
+	c.Assert(report.Violations[5].Index, qt.Equals, 5)
+
+	p.Vehicle = nil
+	c.Assert(p.CheckLoads().OK(), qt.Equals, true)
+}
+
+func TestStepLoadJSON(t *testing.T) {
+	c := qt.New(t)
+	l := StepLoad{Index: 3, RouteStep: RouteStep{Type: StepPickup, OrderID: "p"}, Load: Loads{"weight": 900}}
+
+	b, err := json.Marshal(l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"Index":3,"type":"pickup","orderId":"p","Load":{"weight":900}}`)
+
+	var got StepLoad
+	c.Assert(json.Unmarshal(b, &got), qt.IsNil)
+	c.Assert(got, qt.DeepEquals, l)
+}
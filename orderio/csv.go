@@ -0,0 +1,33 @@
+package orderio
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// ReadCSV reads orders from CSV data whose first row holds the column headers
+// named in m. Each row is validated with Order.Validate. Rows with errors are
+// skipped and reported as RowErrors, alongside the orders read from the
+// other rows.
+func ReadCSV(r io.Reader, m Mapping) ([]workwave.Order, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CSV")
+	}
+	return decode(records, m)
+}
+
+// WriteCSV writes orders as CSV, with a header row followed by a row per
+// order, using the columns mapped in m.
+func WriteCSV(w io.Writer, m Mapping, orders []workwave.Order) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(encode(orders, m)); err != nil {
+		return errors.Wrap(err, "failed to write CSV")
+	}
+	return nil
+}
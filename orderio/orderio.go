@@ -0,0 +1,504 @@
+// Package orderio reads and writes WorkWave orders as CSV and XLSX
+// spreadsheets, using a configurable mapping between order fields and columns.
+package orderio
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+const defaultSeparator = ";"
+
+// Mapping maps order fields to the headers of the spreadsheet columns holding
+// them. Fields with an empty header are not read or written.
+//
+// Lists, such as eligibility dates, time windows and tags, are held in a
+// single cell separated by Separator. Dates are in the format yyyyMMdd, time
+// windows in the format HH:MM-HH:MM, coordinates in degrees and service times
+// in seconds.
+type Mapping struct {
+	Name         string
+	Priority     string
+	EligibleOn   string // Eligible on any of the listed dates
+	EligibleBy   string // Eligible up to the date; orders without either are eligible on any date
+	ForceVehicle string
+	Service      string            // true or false
+	Loads        map[string]string // load name -> header
+	Pickup       StepMapping
+	Delivery     StepMapping
+	// Separator separates the items of list fields. Defaults to ";".
+	Separator string
+}
+
+// StepMapping maps the fields of an order step to column headers. A step is
+// only created for a row if at least one of its columns is not empty.
+type StepMapping struct {
+	Address      string
+	Lat          string
+	Lng          string
+	DepotID      string
+	TimeWindows  string
+	ServiceTime  string
+	Notes        string
+	TagsIn       string
+	TagsOut      string
+	CustomFields map[string]string // custom field name -> header
+}
+
+// DefaultMapping returns a Mapping of every order field to a column named
+// after it, ie "delivery_address", without loads or custom fields.
+func DefaultMapping() Mapping {
+	return Mapping{
+		Name:         "name",
+		Priority:     "priority",
+		EligibleOn:   "eligible_on",
+		EligibleBy:   "eligible_by",
+		ForceVehicle: "force_vehicle_id",
+		Service:      "is_service",
+		Pickup:       defaultStepMapping("pickup"),
+		Delivery:     defaultStepMapping("delivery"),
+	}
+}
+
+func defaultStepMapping(prefix string) StepMapping {
+	return StepMapping{
+		Address:     prefix + "_address",
+		Lat:         prefix + "_lat",
+		Lng:         prefix + "_lng",
+		DepotID:     prefix + "_depot_id",
+		TimeWindows: prefix + "_time_windows",
+		ServiceTime: prefix + "_service_time_sec",
+		Notes:       prefix + "_notes",
+		TagsIn:      prefix + "_tags_in",
+		TagsOut:     prefix + "_tags_out",
+	}
+}
+
+// RowError is a problem with a row of a spreadsheet.
+type RowError struct {
+	Row    int    // 1-based row number, where row 1 holds the headers
+	Column string // header of the offending column, if known
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d, column %q: %v", e.Row, e.Column, e.Err)
+}
+
+// RowErrors is a list of problems found while reading a spreadsheet.
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// column reads and writes a single order field as a cell.
+type column struct {
+	header string
+	get    func(o *workwave.Order) string
+	set    func(o *workwave.Order, v string) error
+}
+
+func (m Mapping) separator() string {
+	if m.Separator == "" {
+		return defaultSeparator
+	}
+	return m.Separator
+}
+
+// columns returns the mapped columns in a stable order.
+func (m Mapping) columns() []column {
+	sep := m.separator()
+	var cols []column
+	add := func(header string, get func(o *workwave.Order) string, set func(o *workwave.Order, v string) error) {
+		if header != "" {
+			cols = append(cols, column{header: header, get: get, set: set})
+		}
+	}
+
+	add(m.Name,
+		func(o *workwave.Order) string { return o.Name },
+		func(o *workwave.Order, v string) error { o.Name = v; return nil })
+	add(m.Priority,
+		func(o *workwave.Order) string { return strconv.Itoa(o.Priority) },
+		func(o *workwave.Order, v string) (err error) {
+			o.Priority, err = strconv.Atoi(strings.TrimSpace(v))
+			return err
+		})
+	add(m.EligibleOn,
+		func(o *workwave.Order) string { return joinDates(o.Eligibility.OnDates, sep) },
+		func(o *workwave.Order, v string) error {
+			dates, err := splitDates(v, sep)
+			if err != nil {
+				return err
+			}
+			o.Eligibility = workwave.Eligibility{Type: workwave.EligibilityOn, OnDates: dates}
+			return nil
+		})
+	add(m.EligibleBy,
+		func(o *workwave.Order) string {
+			if o.Eligibility.ByDate == nil {
+				return ""
+			}
+			return o.Eligibility.ByDate.String()
+		},
+		func(o *workwave.Order, v string) error {
+			if o.Eligibility.Type == workwave.EligibilityOn {
+				return errors.New("cannot be combined with eligible on dates")
+			}
+			d, err := workwave.ParseDate(strings.TrimSpace(v))
+			if err != nil {
+				return err
+			}
+			o.Eligibility = workwave.Eligibility{Type: workwave.EligibilityBy, ByDate: &d}
+			return nil
+		})
+	add(m.ForceVehicle,
+		func(o *workwave.Order) string { return string(o.ForceVehicleID) },
+		func(o *workwave.Order, v string) error {
+			o.ForceVehicleID = workwave.VehicleID(strings.TrimSpace(v))
+			return nil
+		})
+	add(m.Service,
+		func(o *workwave.Order) string { return strconv.FormatBool(o.IsService) },
+		func(o *workwave.Order, v string) (err error) {
+			o.IsService, err = strconv.ParseBool(strings.TrimSpace(v))
+			return err
+		})
+
+	for _, load := range sortedKeys(m.Loads) {
+		load := load
+		add(m.Loads[load],
+			func(o *workwave.Order) string {
+				if n, ok := o.Loads[workwave.LoadDimension(load)]; ok {
+					return strconv.Itoa(n)
+				}
+				return ""
+			},
+			func(o *workwave.Order, v string) error {
+				n, err := strconv.Atoi(strings.TrimSpace(v))
+				if err != nil {
+					return err
+				}
+				if o.Loads == nil {
+					o.Loads = make(workwave.Loads)
+				}
+				o.Loads[workwave.LoadDimension(load)] = n
+				return nil
+			})
+	}
+
+	for _, s := range []struct {
+		m    StepMapping
+		step func(o *workwave.Order, create bool) *workwave.OrderStep
+	}{
+		{m.Pickup, pickup},
+		{m.Delivery, delivery},
+	} {
+		for _, sc := range s.m.columns(sep) {
+			sc, step := sc, s.step
+			add(sc.header,
+				func(o *workwave.Order) string {
+					if st := step(o, false); st != nil {
+						return sc.get(st)
+					}
+					return ""
+				},
+				func(o *workwave.Order, v string) error { return sc.set(step(o, true), v) })
+		}
+	}
+	return cols
+}
+
+func pickup(o *workwave.Order, create bool) *workwave.OrderStep {
+	if o.Pickup == nil && create {
+		o.Pickup = &workwave.OrderStep{}
+	}
+	return o.Pickup
+}
+
+func delivery(o *workwave.Order, create bool) *workwave.OrderStep {
+	if o.Delivery == nil && create {
+		o.Delivery = &workwave.OrderStep{}
+	}
+	return o.Delivery
+}
+
+// stepColumn reads and writes a single order step field as a cell.
+type stepColumn struct {
+	header string
+	get    func(s *workwave.OrderStep) string
+	set    func(s *workwave.OrderStep, v string) error
+}
+
+func (m StepMapping) columns(sep string) []stepColumn {
+	var cols []stepColumn
+	add := func(header string, get func(s *workwave.OrderStep) string, set func(s *workwave.OrderStep, v string) error) {
+		if header != "" {
+			cols = append(cols, stepColumn{header: header, get: get, set: set})
+		}
+	}
+
+	add(m.Address,
+		func(s *workwave.OrderStep) string { return s.Location.Address },
+		func(s *workwave.OrderStep, v string) error { s.Location.Address = v; return nil })
+	add(m.Lat,
+		func(s *workwave.OrderStep) string {
+			if s.Location.LatLng == nil {
+				return ""
+			}
+			return strconv.FormatFloat(s.Location.LatLng.Lat(), 'f', -1, 64)
+		},
+		func(s *workwave.OrderStep, v string) error {
+			lat, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return err
+			}
+			ll := latLng(s)
+			*ll = workwave.NewLatLng(lat, ll.Lng())
+			return nil
+		})
+	add(m.Lng,
+		func(s *workwave.OrderStep) string {
+			if s.Location.LatLng == nil {
+				return ""
+			}
+			return strconv.FormatFloat(s.Location.LatLng.Lng(), 'f', -1, 64)
+		},
+		func(s *workwave.OrderStep, v string) error {
+			lng, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return err
+			}
+			ll := latLng(s)
+			*ll = workwave.NewLatLng(ll.Lat(), lng)
+			return nil
+		})
+	add(m.DepotID,
+		func(s *workwave.OrderStep) string { return s.DepotID },
+		func(s *workwave.OrderStep, v string) error { s.DepotID = strings.TrimSpace(v); return nil })
+	add(m.TimeWindows,
+		func(s *workwave.OrderStep) string { return joinTimeWindows(s.TimeWindows, sep) },
+		func(s *workwave.OrderStep, v string) (err error) {
+			s.TimeWindows, err = splitTimeWindows(v, sep)
+			return err
+		})
+	add(m.ServiceTime,
+		func(s *workwave.OrderStep) string { return strconv.Itoa(s.ServiceTimeSec) },
+		func(s *workwave.OrderStep, v string) (err error) {
+			s.ServiceTimeSec, err = strconv.Atoi(strings.TrimSpace(v))
+			return err
+		})
+	add(m.Notes,
+		func(s *workwave.OrderStep) string { return s.Notes },
+		func(s *workwave.OrderStep, v string) error { s.Notes = v; return nil })
+	add(m.TagsIn,
+		func(s *workwave.OrderStep) string { return strings.Join(s.TagsIn, sep) },
+		func(s *workwave.OrderStep, v string) error { s.TagsIn = split(v, sep); return nil })
+	add(m.TagsOut,
+		func(s *workwave.OrderStep) string { return strings.Join(s.TagsOut, sep) },
+		func(s *workwave.OrderStep, v string) error { s.TagsOut = split(v, sep); return nil })
+
+	for _, field := range sortedKeys(m.CustomFields) {
+		field := field
+		add(m.CustomFields[field],
+			func(s *workwave.OrderStep) string { return s.CustomFields[field] },
+			func(s *workwave.OrderStep, v string) error {
+				if s.CustomFields == nil {
+					s.CustomFields = make(map[string]string)
+				}
+				s.CustomFields[field] = v
+				return nil
+			})
+	}
+	return cols
+}
+
+func latLng(s *workwave.OrderStep) *workwave.LatLng {
+	if s.Location.LatLng == nil {
+		s.Location.LatLng = &workwave.LatLng{}
+	}
+	return s.Location.LatLng
+}
+
+// decode converts records, the first of which holds the headers, into orders.
+// Rows with errors are skipped and reported in the returned RowErrors. A byte
+// order mark, written by spreadsheets before the first header, is ignored.
+func decode(records [][]string, m Mapping) ([]workwave.Order, error) {
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+
+	index := make(map[string]int)
+	for i, h := range records[0] {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if _, ok := index[h]; ok {
+			return nil, errors.Errorf("duplicate column %q", h)
+		}
+		index[h] = i
+	}
+	cols := m.columns()
+	for _, col := range cols {
+		if _, ok := index[col.header]; !ok {
+			return nil, errors.Errorf("missing column %q", col.header)
+		}
+	}
+
+	var orders []workwave.Order
+	var errs RowErrors
+	for r, record := range records[1:] {
+		row := r + 2
+		if isBlank(record) {
+			continue
+		}
+
+		o := workwave.Order{Eligibility: workwave.Eligibility{Type: workwave.EligibilityAny}}
+		var rowErrs RowErrors
+		for _, col := range cols {
+			i := index[col.header]
+			if i >= len(record) {
+				continue
+			}
+			// Free text such as notes is kept as is, only blank cells
+			// are skipped.
+			v := record[i]
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			if err := col.set(&o, v); err != nil {
+				rowErrs = append(rowErrs, &RowError{Row: row, Column: col.header, Err: err})
+			}
+		}
+		if len(rowErrs) == 0 {
+			if err := o.Validate(); err != nil {
+				rowErrs = append(rowErrs, &RowError{Row: row, Err: err})
+			}
+		}
+
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		orders = append(orders, o)
+	}
+
+	if len(errs) > 0 {
+		return orders, errs
+	}
+	return orders, nil
+}
+
+// encode converts orders into records, the first of which holds the headers.
+func encode(orders []workwave.Order, m Mapping) [][]string {
+	cols := m.columns()
+	records := make([][]string, 0, len(orders)+1)
+
+	header := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = col.header
+	}
+	records = append(records, header)
+
+	for n := range orders {
+		record := make([]string, len(cols))
+		for i, col := range cols {
+			record[i] = col.get(&orders[n])
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func split(v, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(v, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func splitDates(v, sep string) ([]workwave.Date, error) {
+	var dates []workwave.Date
+	for _, item := range split(v, sep) {
+		d, err := workwave.ParseDate(item)
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+	return dates, nil
+}
+
+func joinDates(dates []workwave.Date, sep string) string {
+	items := make([]string, len(dates))
+	for i, d := range dates {
+		items[i] = d.String()
+	}
+	return strings.Join(items, sep)
+}
+
+func splitTimeWindows(v, sep string) ([]workwave.TimeWindow, error) {
+	var tws []workwave.TimeWindow
+	for _, item := range split(v, sep) {
+		bounds := strings.SplitN(item, "-", 2)
+		if len(bounds) != 2 {
+			return nil, errors.Errorf("invalid time window %q", item)
+		}
+		start, err := workwave.ParseSecOfDay(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, err
+		}
+		end, err := workwave.ParseSecOfDay(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return nil, err
+		}
+		tws = append(tws, workwave.TimeWindow{StartSec: start, EndSec: end})
+	}
+	return tws, nil
+}
+
+func joinTimeWindows(tws []workwave.TimeWindow, sep string) string {
+	items := make([]string, len(tws))
+	for i, tw := range tws {
+		items[i] = tw.StartSec.String() + "-" + tw.EndSec.String()
+	}
+	return strings.Join(items, sep)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
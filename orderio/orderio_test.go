@@ -0,0 +1,156 @@
+package orderio
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+	"github.com/tkh/go-workwave/internal/fixture"
+)
+
+func fixtureOrders(c *qt.C) []workwave.Order {
+	return fixture.Load(c, "orders-list.json").SortedOrders()
+}
+
+func fixtureMapping() Mapping {
+	m := DefaultMapping()
+	m.Loads = map[string]string{
+		"frozen ton":  "frozen_tons",
+		"regular ton": "regular_tons",
+		"people":      "people",
+	}
+	m.Delivery.CustomFields = map[string]string{
+		"Phone Extensions ":      "phone_extension",
+		"Building Entrance Code": "entrance_code",
+	}
+	return m
+}
+
+func TestReadCSV(t *testing.T) {
+	m := Mapping{
+		Name:       "Customer",
+		EligibleOn: "Dates",
+		Loads:      map[string]string{"pallets": "Pallets"},
+		Delivery: StepMapping{
+			Address:      "Address",
+			TimeWindows:  "Windows",
+			ServiceTime:  "Service (s)",
+			TagsIn:       "Requires",
+			CustomFields: map[string]string{"phone": "Phone"},
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		c := qt.New(t)
+		in := "Customer,Address,Windows,Dates,Pallets,Service (s),Requires,Phone,Ignored\n" +
+			"Acme,\"710 7th Ave, Jasper\",09:00-11:00;13:00-15:30,20151204;20151205,2,600,frozen; tail-lift,555-0100,x\n" +
+			",,,,,,,,\n" +
+			"Globex,701 Birmingham Ave,,,,,,,\n"
+		orders, err := ReadCSV(strings.NewReader(in), m)
+		c.Assert(err, qt.IsNil)
+		c.Assert(orders, qt.DeepEquals, []workwave.Order{
+			{
+				Name: "Acme",
+				Eligibility: workwave.Eligibility{
+					Type: workwave.EligibilityOn,
+					OnDates: []workwave.Date{
+						workwave.NewDate(2015, time.December, 4),
+						workwave.NewDate(2015, time.December, 5),
+					},
+				},
+				Loads: workwave.Loads{"pallets": 2},
+				Delivery: &workwave.OrderStep{
+					Location: workwave.Location{Address: "710 7th Ave, Jasper"},
+					TimeWindows: []workwave.TimeWindow{
+						{StartSec: 32400, EndSec: 39600},
+						{StartSec: 46800, EndSec: 55800},
+					},
+					ServiceTimeSec: 600,
+					TagsIn:         []string{"frozen", "tail-lift"},
+					CustomFields:   map[string]string{"phone": "555-0100"},
+				},
+			},
+			{
+				Name:        "Globex",
+				Eligibility: workwave.Eligibility{Type: workwave.EligibilityAny},
+				Delivery: &workwave.OrderStep{
+					Location: workwave.Location{Address: "701 Birmingham Ave"},
+				},
+			},
+		})
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		c := qt.New(t)
+		in := "Customer,Address,Windows,Dates,Pallets,Service (s),Requires,Phone\n" +
+			"Acme,710 7th Ave,9am-11am,,,,,\n" +
+			"Initech,1 Main St,,2015-12-04,two,,,\n" +
+			",1 Main St,11:00-09:00,,,,,\n" +
+			"Globex,701 Birmingham Ave,,,,,,\n"
+		orders, err := ReadCSV(strings.NewReader(in), m)
+		c.Assert(err, qt.ErrorMatches, `row 2, column "Windows": invalid time of day "9am"; `+
+			`row 3, column "Dates": invalid date "2015-12-04".*; `+
+			`row 3, column "Pallets": .*invalid syntax; `+
+			`row 4: name: is required; delivery.timeWindows\[0\]: must end after it starts at 11:00`)
+		c.Assert(len(err.(RowErrors)), qt.Equals, 4)
+		c.Assert(len(orders), qt.Equals, 1)
+		c.Assert(orders[0].Name, qt.Equals, "Globex")
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := ReadCSV(strings.NewReader("Customer,Address\nAcme,710 7th Ave\n"), m)
+		c.Assert(err, qt.ErrorMatches, `missing column "Dates"`)
+	})
+
+	t.Run("byte order mark", func(t *testing.T) {
+		c := qt.New(t)
+		in := "\ufeffCustomer,Address,Windows,Dates,Pallets,Service (s),Requires,Phone,,\n" +
+			"Acme,710 7th Ave,,,,,,,,\n"
+		orders, err := ReadCSV(strings.NewReader(in), m)
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(orders), qt.Equals, 1)
+		c.Assert(orders[0].Name, qt.Equals, "Acme")
+	})
+
+	t.Run("duplicate column", func(t *testing.T) {
+		c := qt.New(t)
+		in := "Customer,Address,Windows,Dates,Pallets,Service (s),Requires,Phone, Address\n"
+		_, err := ReadCSV(strings.NewReader(in), m)
+		c.Assert(err, qt.ErrorMatches, `duplicate column "Address"`)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		c := qt.New(t)
+		_, err := ReadCSV(strings.NewReader(""), m)
+		c.Assert(err, qt.ErrorMatches, "missing header row")
+	})
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	m := fixtureMapping()
+
+	var first bytes.Buffer
+	c.Assert(WriteCSV(&first, m, fixtureOrders(c)), qt.IsNil)
+
+	want, err := ioutil.ReadFile(filepath.Join("testdata", "orders.csv"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(first.String(), qt.Equals, string(want))
+
+	orders, err := ReadCSV(bytes.NewReader(first.Bytes()), m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(orders), qt.Equals, 7)
+	c.Assert(orders[3].Loads, qt.DeepEquals, workwave.Loads{"frozen ton": 100, "regular ton": 300})
+	c.Assert(orders[5].ForceVehicleID, qt.Equals, workwave.VehicleID("31656f79-cba7-4bcf-a959-e3fe3f7ca2a7"))
+	c.Assert(*orders[5].Pickup.Location.LatLng, qt.Equals, workwave.LatLng{33480873, -86788220})
+
+	var second bytes.Buffer
+	c.Assert(WriteCSV(&second, m, orders), qt.IsNil)
+	c.Assert(second.String(), qt.Equals, first.String())
+}
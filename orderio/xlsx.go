@@ -0,0 +1,283 @@
+package orderio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// Only the parts of the Office Open XML format needed to exchange a single
+// sheet of text cells are supported, which avoids depending on a full
+// spreadsheet library.
+
+const (
+	xlsxRelsNS    = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxSheet     = "xl/worksheets/sheet1.xml"
+	xlsxSheetName = "Orders"
+)
+
+// ReadXLSX reads orders from the first sheet of an XLSX workbook, whose first
+// row holds the column headers named in m. Errors are reported as for
+// ReadCSV.
+func ReadXLSX(r io.ReaderAt, size int64, m Mapping) ([]workwave.Order, error) {
+	records, err := readXLSXSheet(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read XLSX")
+	}
+	return decode(records, m)
+}
+
+// WriteXLSX writes orders as an XLSX workbook with a single sheet, holding a
+// header row followed by a row per order, using the columns mapped in m.
+func WriteXLSX(w io.Writer, m Mapping, orders []workwave.Order) error {
+	if err := writeXLSXSheet(w, encode(orders, m)); err != nil {
+		return errors.Wrap(err, "failed to write XLSX")
+	}
+	return nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string   `xml:"r,attr"`
+			T  string   `xml:"t,attr"`
+			V  string   `xml:"v"`
+			IS xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSXSheet(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var wb xlsxWorkbook
+	if err := decodeXLSXPart(files, "xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, errors.New("workbook has no sheets")
+	}
+	var rels xlsxRelationships
+	if err := decodeXLSXPart(files, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheet := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == wb.Sheets[0].RID {
+			sheet = rel.Target
+		}
+	}
+	switch {
+	case sheet == "":
+		return nil, errors.Errorf("missing relationship for sheet %q", wb.Sheets[0].Name)
+	case strings.HasPrefix(sheet, "/"):
+		sheet = strings.TrimPrefix(sheet, "/")
+	default:
+		sheet = path.Join("xl", sheet)
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXLSXPart(files, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var ws xlsxWorksheet
+	if err := decodeXLSXPart(files, sheet, &ws); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for _, row := range ws.Rows {
+		n := row.R
+		if n <= 0 {
+			n = len(records) + 1
+		}
+		for len(records) < n {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for _, c := range row.Cells {
+			col := len(record)
+			if c.R != "" {
+				if col, err = xlsxColumn(c.R); err != nil {
+					return nil, err
+				}
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			switch c.T {
+			case "s":
+				var i int
+				if _, err := fmt.Sscan(c.V, &i); err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, errors.Errorf("invalid shared string %q in cell %s", c.V, c.R)
+				}
+				record[col] = shared.Items[i].String()
+			case "inlineStr":
+				record[col] = c.IS.String()
+			default:
+				record[col] = c.V
+			}
+		}
+		records[n-1] = record
+	}
+	return records, nil
+}
+
+func decodeXLSXPart(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return errors.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", name)
+	}
+	return nil
+}
+
+// xlsxColumn returns the 0-based column of a cell reference, ie 27 for AB3.
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A') + 1
+		n++
+	}
+	if n == 0 {
+		return 0, errors.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// xlsxColumnName returns the letters of the 0-based column, ie AB for 27.
+func xlsxColumnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+var xlsxStaticParts = []struct {
+	name, content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/` + xlsxSheet + `" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + xlsxRelsNS + `/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + xlsxRelsNS + `">` +
+		`<sheets><sheet name="` + xlsxSheetName + `" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + xlsxRelsNS + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func writeXLSXSheet(w io.Writer, records [][]string) error {
+	zw := zip.NewWriter(w)
+	for _, p := range xlsxStaticParts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return err
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, record := range records {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, v := range record {
+			if v == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(c), r+1)
+			if err := xml.EscapeText(&b, []byte(v)); err != nil {
+				return err
+			}
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+
+	f, err := zw.Create(xlsxSheet)
+	if err != nil {
+		return err
+	}
+	if _, err := b.WriteTo(f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
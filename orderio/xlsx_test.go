@@ -0,0 +1,115 @@
+package orderio
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	m := fixtureMapping()
+
+	var csvOut bytes.Buffer
+	c.Assert(WriteCSV(&csvOut, m, fixtureOrders(c)), qt.IsNil)
+	want, err := ReadCSV(bytes.NewReader(csvOut.Bytes()), m)
+	c.Assert(err, qt.IsNil)
+
+	var b bytes.Buffer
+	c.Assert(WriteXLSX(&b, m, fixtureOrders(c)), qt.IsNil)
+	orders, err := ReadXLSX(bytes.NewReader(b.Bytes()), int64(b.Len()), m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(orders, qt.DeepEquals, want)
+}
+
+func TestReadXLSX(t *testing.T) {
+	m := Mapping{
+		Name:     "Customer",
+		Delivery: StepMapping{Address: "Address", Notes: "Notes"},
+	}
+
+	t.Run("shared strings", func(t *testing.T) {
+		c := qt.New(t)
+		b := buildXLSX(c, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + xlsxRelsNS + `">` +
+				`<sheets><sheet name="Data" sheetId="3" r:id="rId7"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId7" Type="` + xlsxRelsNS + `/worksheet" Target="/xl/worksheets/data.xml"/></Relationships>`,
+			"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+				`<si><t>Customer</t></si><si><t>Address</t></si><si><t>Notes</t></si>` +
+				`<si><r><t>Ac</t></r><r><t>me</t></r></si></sst>`,
+			"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+				`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>1</v></c></row>` +
+				`<row r="3"><c r="A3" t="s"><v>3</v></c><c r="C3"><v>42</v></c><c r="D3" t="inlineStr"><is><t>710 7th Ave</t></is></c></row>` +
+				`</sheetData></worksheet>`,
+		})
+		orders, err := ReadXLSX(bytes.NewReader(b), int64(len(b)), m)
+		c.Assert(err, qt.IsNil)
+		c.Assert(orders, qt.DeepEquals, []workwave.Order{{
+			Name:        "Acme",
+			Eligibility: workwave.Eligibility{Type: workwave.EligibilityAny},
+			Delivery: &workwave.OrderStep{
+				Location: workwave.Location{Address: "710 7th Ave"},
+				Notes:    "42",
+			},
+		}})
+	})
+
+	t.Run("invalid shared string", func(t *testing.T) {
+		c := qt.New(t)
+		b := buildXLSX(c, map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + xlsxRelsNS + `">` +
+				`<sheets><sheet name="Data" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" Type="` + xlsxRelsNS + `/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+				`<row r="1"><c r="A1" t="s"><v>5</v></c></row></sheetData></worksheet>`,
+		})
+		_, err := ReadXLSX(bytes.NewReader(b), int64(len(b)), m)
+		c.Assert(err, qt.ErrorMatches, `failed to read XLSX: invalid shared string "5" in cell A1`)
+	})
+
+	t.Run("missing workbook", func(t *testing.T) {
+		c := qt.New(t)
+		b := buildXLSX(c, map[string]string{"docProps/app.xml": `<Properties/>`})
+		_, err := ReadXLSX(bytes.NewReader(b), int64(len(b)), m)
+		c.Assert(err, qt.ErrorMatches, "failed to read XLSX: missing xl/workbook.xml")
+	})
+
+	t.Run("not a zip", func(t *testing.T) {
+		c := qt.New(t)
+		b := []byte("name,address\n")
+		_, err := ReadXLSX(bytes.NewReader(b), int64(len(b)), m)
+		c.Assert(err, qt.ErrorMatches, "failed to read XLSX: .*")
+	})
+}
+
+func TestXLSXColumn(t *testing.T) {
+	c := qt.New(t)
+	for col, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		c.Assert(xlsxColumnName(col), qt.Equals, name)
+		got, err := xlsxColumn(name + "12")
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, col)
+	}
+	_, err := xlsxColumn("12")
+	c.Assert(err, qt.ErrorMatches, `invalid cell reference "12"`)
+}
+
+func buildXLSX(c *qt.C, parts map[string]string) []byte {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for name, content := range parts {
+		f, err := zw.Create(name)
+		c.Assert(err, qt.IsNil)
+		_, err = io.WriteString(f, xml.Header+content)
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(zw.Close(), qt.IsNil)
+	return b.Bytes()
+}
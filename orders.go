@@ -2,14 +2,18 @@ package workwave
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 const (
 	ordersBasePath = "/api/v1/territories/%s/orders"
+	orderPath      = ordersBasePath + "/%s"
 )
 
 // OrdersService is an interface to orders in the WorkWave API.
@@ -19,6 +23,27 @@ type OrdersService interface {
 	Add(context.Context, OrdersAddInput) (string, error)
 }
 
+// The following optional interfaces are implemented by the OrdersService of a
+// Client, ie
+//
+//	m, err := client.Orders.(workwave.OrdersMapLister).ListMap(ctx, input)
+
+// OrdersMapLister lists orders by ID.
+type OrdersMapLister interface {
+	ListMap(context.Context, OrdersListInput) (map[string]Order, error)
+	GetMap(context.Context, OrdersGetInput) (map[string]Order, error)
+}
+
+// OrdersStreamer lists orders as they are decoded.
+type OrdersStreamer interface {
+	ListStream(context.Context, OrdersListInput) (*OrderIterator, error)
+}
+
+// OrdersUpdater updates existing orders.
+type OrdersUpdater interface {
+	Update(context.Context, OrdersUpdateInput) (string, error)
+}
+
 type ordersService struct {
 	client *Client
 }
@@ -26,49 +51,160 @@ type ordersService struct {
 // Order represents an Order in the WorkWave API
 // This structure can be used as input for order calls by omitting ID.
 type Order struct {
-	ID             string         `json:"id,omitempty"`
-	Name           string         `json:"name,omitempty"`
-	Eligibility    Eligibility    `json:"eligibility,omitempty"`
-	ForceVehicleID interface{}    `json:"forceVehicleId,omitempty"`
-	Priority       int            `json:"priority,omitempty"`
-	Loads          map[string]int `json:"loads,omitempty"`
-	Pickup         *OrderStep     `json:"pickup,omitempty"`
-	Delivery       *OrderStep     `json:"delivery,omitempty"`
-	IsService      bool           `json:"isService,omitempty"`
+	ID             string      `json:"id,omitempty"`
+	Name           string      `json:"name,omitempty"`
+	Eligibility    Eligibility `json:"eligibility,omitempty"`
+	ForceVehicleID VehicleID   `json:"forceVehicleId,omitempty"`
+	Priority       int         `json:"priority,omitempty"`
+	Loads          Loads       `json:"loads,omitempty"`
+	Pickup         *OrderStep  `json:"pickup,omitempty"`
+	Delivery       *OrderStep  `json:"delivery,omitempty"`
+	IsService      bool        `json:"isService,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type orderJSON Order
+
+// MarshalJSON implements json.Marshaler. A zero Eligibility is omitted, which
+// omitempty does not do for structs.
+func (o Order) MarshalJSON() ([]byte, error) {
+	v := struct {
+		orderJSON
+		Eligibility *Eligibility `json:"eligibility,omitempty"`
+	}{orderJSON: orderJSON(o)}
+	if !o.Eligibility.isZero() {
+		v.Eligibility = &o.Eligibility
+	}
+	return marshalObject(v, o.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *Order) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*orderJSON)(o), &o.Extra)
+}
+
+// VehicleID is the ID of a vehicle which may be null, such as the vehicle an
+// order is forced onto. The empty ID is null.
+type VehicleID string
+
+// MarshalJSON implements json.Marshaler.
+func (id VehicleID) MarshalJSON() ([]byte, error) {
+	if id == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(string(id))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *VehicleID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Wrap(err, "failed to decode vehicle ID")
+	}
+	*id = VehicleID(s)
+	return nil
 }
 
 // Eligibility represents Eligibility for an Order in the WorkWave API.
 type Eligibility struct {
-	Type    string   `json:"type,omitempty"`    // One of: on, by, any
-	ByDate  string   `json:"byDate,omitempty"`  // Used when type = by, format: yyyyMMdd
-	OnDates []string `json:"onDates,omitempty"` // Used when type = on, format: yyyyMMdd
+	Type    EligibilityType `json:"type,omitempty"`
+	ByDate  *Date           `json:"byDate,omitempty"`  // Used when type = by
+	OnDates []Date          `json:"onDates,omitempty"` // Used when type = on
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+func (e Eligibility) isZero() bool {
+	return e.Type == "" && e.ByDate == nil && len(e.OnDates) == 0 && len(e.Extra) == 0
+}
+
+type eligibilityJSON Eligibility
+
+// MarshalJSON implements json.Marshaler.
+func (e Eligibility) MarshalJSON() ([]byte, error) {
+	return marshalObject(eligibilityJSON(e), e.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (e *Eligibility) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*eligibilityJSON)(e), &e.Extra)
 }
 
 // Location represents a Location in the WorkWave API.
 type Location struct {
-	Address string  `json:"address,omitempty"`
-	LatLng  *[2]int `json:"latLng,omitempty"` // ie, {33817872, -87266893}
-	Status  string  `json:"status,omitempty"`
+	Address string         `json:"address,omitempty"`
+	LatLng  *LatLng        `json:"latLng,omitempty"`
+	Status  LocationStatus `json:"status,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type locationJSON Location
+
+// MarshalJSON implements json.Marshaler.
+func (l Location) MarshalJSON() ([]byte, error) {
+	return marshalObject(locationJSON(l), l.Extra)
 }
 
-// TimeWindow represents a time window in the WorkWave API.
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *Location) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*locationJSON)(l), &l.Extra)
+}
+
+// TimeWindow represents a time window in the WorkWave API. Unlike other
+// models, it does not keep unknown fields, so that it remains comparable: they
+// are dropped, and are not reported by WithStrictDecoding.
 type TimeWindow struct {
-	StartSec int `json:"startSec,omitempty"`
-	EndSec   int `json:"endSec,omitempty"`
+	StartSec SecOfDay `json:"startSec,omitempty"`
+	EndSec   SecOfDay `json:"endSec,omitempty"`
+}
+
+// Bounds returns the instants at which the time window starts and ends on d
+// in loc.
+func (tw TimeWindow) Bounds(d Date, loc *time.Location) (start, end time.Time) {
+	return tw.StartSec.Time(d, loc), tw.EndSec.Time(d, loc)
 }
 
 // OrderStep represents an OrderStep within an Order in the WorkWave API.
 // An OrderStep can be `pickup` or `delivery`.
 type OrderStep struct {
-	DepotID              string                `json:"depotId,omitempty"`
-	Location             Location              `json:"location,omitempty"`
-	TimeWindows          []TimeWindow          `json:"timeWindows,omitempty"`
-	TimeWindowExceptions map[string]TimeWindow `json:"timeWindowExceptions,omitempty"`
-	Notes                string                `json:"notes,omitempty"`
-	ServiceTimeSec       int                   `json:"serviceTimeSec,omitempty"`
-	TagsIn               []string              `json:"tagsIn,omitempty"`
-	TagsOut              []string              `json:"tagsOut,omitempty"`
-	CustomFields         map[string]string     `json:"customFields,omitempty"`
+	DepotID              string              `json:"depotId,omitempty"`
+	Location             Location            `json:"location,omitempty"`
+	TimeWindows          []TimeWindow        `json:"timeWindows,omitempty"`
+	TimeWindowExceptions map[Date]TimeWindow `json:"timeWindowExceptions,omitempty"`
+	Notes                string              `json:"notes,omitempty"`
+	ServiceTimeSec       int                 `json:"serviceTimeSec,omitempty"`
+	TagsIn               []string            `json:"tagsIn,omitempty"`
+	TagsOut              []string            `json:"tagsOut,omitempty"`
+	CustomFields         map[string]string   `json:"customFields,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type orderStepJSON OrderStep
+
+// MarshalJSON implements json.Marshaler.
+func (s OrderStep) MarshalJSON() ([]byte, error) {
+	return marshalObject(orderStepJSON(s), s.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *OrderStep) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*orderStepJSON)(s), &s.Extra)
+}
+
+// TimeWindowsOn returns the time windows of the step on d, which are those of
+// its exception for d if it has one.
+func (s OrderStep) TimeWindowsOn(d Date) []TimeWindow {
+	if tw, ok := s.TimeWindowExceptions[d]; ok {
+		return []TimeWindow{tw}
+	}
+	return s.TimeWindows
 }
 
 type ordersResponse struct {
@@ -84,7 +220,40 @@ type OrdersListInput struct {
 }
 
 // List retrieves the orders matching the filters provided in the given OrderListInput.
+// Orders are returned in the order of the API response.
 func (svc *ordersService) List(ctx context.Context, i OrdersListInput) ([]Order, error) {
+	it, err := svc.ListStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.all()
+}
+
+// ListMap retrieves the same orders as List, by ID.
+func (svc *ordersService) ListMap(ctx context.Context, i OrdersListInput) (map[string]Order, error) {
+	it, err := svc.ListStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.byID()
+}
+
+// ListStream retrieves the same orders as List, decoding them one at a time
+// as they are read from the response.
+func (svc *ordersService) ListStream(ctx context.Context, i OrdersListInput) (*OrderIterator, error) {
+	req, err := svc.listRequest(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := svc.client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderIterator{s: newObjectStream(res.Body, "orders", svc.client.checkDecoded)}, nil
+}
+
+func (svc *ordersService) listRequest(ctx context.Context, i OrdersListInput) (*http.Request, error) {
 	u := fmt.Sprintf(ordersBasePath, i.TerritoryID)
 	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -104,17 +273,7 @@ func (svc *ordersService) List(ctx context.Context, i OrdersListInput) ([]Order,
 	}
 	req.URL.RawQuery = q.Encode()
 
-	olr := &ordersResponse{}
-	if _, err := svc.client.Do(ctx, req, olr); err != nil {
-		return nil, err
-	}
-
-	var orders []Order
-	for _, order := range olr.Orders {
-		orders = append(orders, order)
-	}
-
-	return orders, nil
+	return req, nil
 }
 
 // OrdersGetInput is used to populate a call Get Orders on the WorkWave API.
@@ -123,8 +282,26 @@ type OrdersGetInput struct {
 	IDs         []string
 }
 
-// Get orders for the given IDs.
+// Get orders for the given IDs. Orders are returned in the order of the API
+// response.
 func (svc *ordersService) Get(ctx context.Context, i OrdersGetInput) ([]Order, error) {
+	it, err := svc.get(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.all()
+}
+
+// GetMap gets the same orders as Get, by ID.
+func (svc *ordersService) GetMap(ctx context.Context, i OrdersGetInput) (map[string]Order, error) {
+	it, err := svc.get(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.byID()
+}
+
+func (svc *ordersService) get(ctx context.Context, i OrdersGetInput) (*OrderIterator, error) {
 	u := fmt.Sprintf(ordersBasePath, i.TerritoryID)
 	b := struct {
 		IDs []string `json:"ids"`
@@ -136,17 +313,16 @@ func (svc *ordersService) Get(ctx context.Context, i OrdersGetInput) ([]Order, e
 		return nil, errors.Wrap(err, "failed to create orders get request")
 	}
 
-	olr := &ordersResponse{}
-	if _, err := svc.client.Do(ctx, req, olr); err != nil {
+	res, err := svc.client.send(ctx, req)
+	if err != nil {
 		return nil, err
 	}
+	return &OrderIterator{s: newObjectStream(res.Body, "orders", svc.client.checkDecoded)}, nil
+}
 
-	var orders []Order
-	for _, order := range olr.Orders {
-		orders = append(orders, order)
-	}
-
-	return orders, nil
+// SortOrdersByID sorts orders by ID.
+func SortOrdersByID(orders []Order) {
+	sort.SliceStable(orders, func(a, b int) bool { return orders[a].ID < orders[b].ID })
 }
 
 // OrdersAddInput is used to populate a call Add Orders on the WorkWave API.
@@ -155,6 +331,9 @@ type OrdersAddInput struct {
 	Orders            []Order `json:"orders"`
 	Strict            bool    `json:"strict"`
 	AcceptBadGeocodes bool    `json:"acceptBadGeocodes"`
+	// Registry is optional. If set, the orders are validated against it
+	// before being submitted.
+	Registry *Registry `json:"-"`
 }
 
 type ordersAddResponse struct {
@@ -164,6 +343,12 @@ type ordersAddResponse struct {
 // Add the given orders to WorkWave via the API.
 // This API call is asynchronous and the WorkWave API `requestId` value will be returned.
 func (svc *ordersService) Add(ctx context.Context, i OrdersAddInput) (string, error) {
+	if i.Registry != nil {
+		if err := i.Registry.ValidateOrders(i.Orders); err != nil {
+			return "", err
+		}
+	}
+
 	u := fmt.Sprintf(ordersBasePath, i.TerritoryID)
 	req, err := svc.client.NewRequest(ctx, http.MethodPost, u, i)
 	if err != nil {
@@ -176,3 +361,58 @@ func (svc *ordersService) Add(ctx context.Context, i OrdersAddInput) (string, er
 	}
 	return oar.RequestID, nil
 }
+
+// OrdersUpdateInput is used to populate a call to Update Order on the WorkWave
+// API. Fields selects the fields of Order which are updated, as dotted paths
+// of their JSON names such as "priority" or "pickup.notes". Selected fields
+// are sent even when empty, so that they can be set to 0, false or "", or
+// cleared with null, while other fields are left unchanged. When Fields is
+// empty, the non-empty fields of Order are updated, and a zero Eligibility is
+// left unchanged.
+type OrdersUpdateInput struct {
+	TerritoryID string
+	OrderID     string // Defaults to Order.ID
+	Order       Order
+	Fields      []string
+	// Registry is optional. If set, the order is validated against it before
+	// being submitted.
+	Registry *Registry
+}
+
+// Update partially updates an order.
+// This API call is asynchronous and the WorkWave API `requestId` value will be returned.
+func (svc *ordersService) Update(ctx context.Context, i OrdersUpdateInput) (string, error) {
+	id := i.OrderID
+	if id == "" {
+		id = i.Order.ID
+	}
+	if id == "" {
+		return "", errors.New("order ID is required")
+	}
+	if i.Registry != nil {
+		if err := i.Registry.Validate(i.Order); err != nil {
+			return "", err
+		}
+	}
+
+	var body interface{} = i.Order
+	if len(i.Fields) > 0 {
+		p, err := patch(i.Order, i.Fields)
+		if err != nil {
+			return "", err
+		}
+		body = p
+	}
+
+	u := fmt.Sprintf(orderPath, i.TerritoryID, id)
+	req, err := svc.client.NewRequest(ctx, http.MethodPatch, u, body)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create order update request")
+	}
+
+	oar := &ordersAddResponse{}
+	if _, err := svc.client.Do(ctx, req, oar); err != nil {
+		return "", err
+	}
+	return oar.RequestID, nil
+}
@@ -2,10 +2,12 @@ package workwave
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path/filepath"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -30,6 +32,31 @@ func TestOrdersList(t *testing.T) {
 	})
 	c.Assert(err, qt.IsNil)
 	c.Assert(len(o), qt.Equals, 7)
+	// Orders are in the order of the response.
+	c.Assert(orderIDs(o), qt.DeepEquals, []string{
+		"4516b2e1-43dc-49a8-8bfb-7190fa60df21",
+		"0d56e7a3-c737-472e-bec9-e2f19d4865d3",
+		"407df645-bca8-4d3d-aa84-6dafcf1296a4",
+		"c5b66e27-eb70-43fb-be54-37a2f7ef723e",
+		"0f397b30-b068-4e4f-8d78-d4b0c231e6c1",
+		"903a7eda-b27d-499c-b70c-58258449470a",
+		"f8ad4ee8-89b6-4e57-8d5d-3c0838d03ea7",
+	})
+
+	m, err := client.Orders.(OrdersMapLister).ListMap(ctx, OrdersListInput{TerritoryID: "territory"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 7)
+	for _, order := range o {
+		c.Check(m[order.ID], qt.DeepEquals, order)
+	}
+}
+
+func orderIDs(orders []Order) []string {
+	var ids []string
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
 }
 
 func TestOrdersGet(t *testing.T) {
@@ -52,7 +79,25 @@ func TestOrdersGet(t *testing.T) {
 	})
 
 	c.Assert(err, qt.IsNil)
-	c.Assert(len(o), qt.Equals, 2)
+	c.Assert(orderIDs(o), qt.DeepEquals, []string{
+		"4516b2e1-43dc-49a8-8bfb-7190fa60df21",
+		"0d56e7a3-c737-472e-bec9-e2f19d4865d3",
+	})
+
+	m, err := client.Orders.(OrdersMapLister).GetMap(ctx, OrdersGetInput{
+		TerritoryID: "territory",
+		IDs:         []string{"4516b2e1-43dc-49a8-8bfb-7190fa60df21"},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 2)
+	c.Assert(m["0d56e7a3-c737-472e-bec9-e2f19d4865d3"], qt.DeepEquals, o[1])
+}
+
+func TestSortOrdersByID(t *testing.T) {
+	c := qt.New(t)
+	orders := []Order{{ID: "c"}, {ID: "a", Name: "1"}, {ID: "b"}, {ID: "a", Name: "2"}}
+	SortOrdersByID(orders)
+	c.Assert(orders, qt.DeepEquals, []Order{{ID: "a", Name: "1"}, {ID: "a", Name: "2"}, {ID: "b"}, {ID: "c"}})
 }
 
 func TestOrdersAdd(t *testing.T) {
@@ -77,3 +122,61 @@ func TestOrdersAdd(t *testing.T) {
 	c.Assert(err, qt.IsNil)
 	c.Assert(rID, qt.Equals, "509900a5-392e-4d34-bcfe-90cc6bf3ad47")
 }
+
+func TestOrderStepTimeWindowsOn(t *testing.T) {
+	c := qt.New(t)
+	exception := NewDate(2015, time.December, 5)
+	s := OrderStep{
+		TimeWindows: []TimeWindow{{StartSec: 30600, EndSec: 37800}, {StartSec: 45000, EndSec: 55800}},
+		TimeWindowExceptions: map[Date]TimeWindow{
+			exception: {StartSec: 50400, EndSec: 54000},
+		},
+	}
+	c.Assert(s.TimeWindowsOn(NewDate(2015, time.December, 4)), qt.DeepEquals, s.TimeWindows)
+	c.Assert(s.TimeWindowsOn(exception), qt.DeepEquals, []TimeWindow{{StartSec: 50400, EndSec: 54000}})
+}
+
+func TestOrdersUpdate(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	var body string
+	mux.HandleFunc("/api/v1/territories/territory/orders/o1", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, qt.Equals, http.MethodPatch)
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		fmt.Fprintf(w, `{"requestId": "509900a5-392e-4d34-bcfe-90cc6bf3ad47"}`)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	rID, err := client.Orders.(OrdersUpdater).Update(ctx, OrdersUpdateInput{
+		TerritoryID: "territory",
+		Order:       Order{ID: "o1", Name: "ignored", Delivery: &OrderStep{TagsIn: []string{"fridge"}}},
+		Fields:      []string{"priority", "isService", "forceVehicleId", "delivery.notes", "delivery.tagsIn"},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(rID, qt.Equals, "509900a5-392e-4d34-bcfe-90cc6bf3ad47")
+	c.Assert(body, qt.Equals, `{"delivery":{"notes":"","tagsIn":["fridge"]},"forceVehicleId":null,"isService":false,"priority":0}`+"\n")
+
+	// Without fields, the non-empty fields are sent.
+	_, err = client.Orders.(OrdersUpdater).Update(ctx, OrdersUpdateInput{
+		TerritoryID: "territory",
+		OrderID:     "o1",
+		Order:       Order{Priority: 2},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(body, qt.Equals, `{"priority":2}`+"\n")
+
+	_, err = client.Orders.(OrdersUpdater).Update(ctx, OrdersUpdateInput{TerritoryID: "territory", Order: Order{Priority: 2}})
+	c.Assert(err, qt.ErrorMatches, "order ID is required")
+
+	_, err = client.Orders.(OrdersUpdater).Update(ctx, OrdersUpdateInput{
+		TerritoryID: "territory",
+		OrderID:     "o1",
+		Fields:      []string{"prio"},
+	})
+	c.Assert(err, qt.ErrorMatches, `invalid field "prio": unknown field prio`)
+}
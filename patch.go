@@ -0,0 +1,89 @@
+package workwave
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// patch returns the JSON object of the given fields of v, a struct. Fields are
+// dotted paths of JSON names, such as "pickup.notes", and are included even
+// when empty, as 0, false, "" or null. Members of an Extra field can also be
+// selected.
+func patch(v interface{}, fields []string) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	for _, f := range fields {
+		if err := patchField(obj, reflect.ValueOf(v), f); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func patchField(obj map[string]interface{}, v reflect.Value, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.Zero(v.Type().Elem())
+				continue
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return errors.Errorf("invalid field %q: %s is not an object", path, strings.Join(names[:i], "."))
+		}
+
+		value, ok := fieldByName(v, name)
+		if !ok {
+			return errors.Errorf("invalid field %q: unknown field %s", path, name)
+		}
+		if i == len(names)-1 {
+			if _, ok := obj[name]; ok {
+				return errors.Errorf("invalid field %q: selected more than once", path)
+			}
+			obj[name] = value.Interface()
+			return nil
+		}
+
+		sub, ok := obj[name].(map[string]interface{})
+		if !ok {
+			if _, set := obj[name]; set {
+				return errors.Errorf("invalid field %q: %s is selected as a whole", path, strings.Join(names[:i+1], "."))
+			}
+			sub = make(map[string]interface{})
+			obj[name] = sub
+		}
+		obj, v = sub, value
+	}
+	return nil
+}
+
+// fieldByName returns the field of struct v with the given JSON name, or the
+// member of its Extra field. Only fields with a JSON tag are API members, so
+// structs encoded otherwise, such as Date, have none.
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag != "" && tag != "-" && tag == name {
+			return v.Field(i), true
+		}
+	}
+	extra := v.FieldByName("Extra")
+	if !extra.IsValid() {
+		return reflect.Value{}, false
+	}
+	if extra, ok := extra.Interface().(map[string]json.RawMessage); ok {
+		if m, ok := extra[name]; ok {
+			return reflect.ValueOf(m), true
+		}
+	}
+	return reflect.Value{}, false
+}
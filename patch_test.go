@@ -0,0 +1,78 @@
+package workwave
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPatch(t *testing.T) {
+	o := Order{
+		Name:     "Order 1",
+		Priority: 0,
+		Pickup: &OrderStep{
+			Location: Location{Address: "a"},
+			Notes:    "n",
+		},
+		Extra: map[string]json.RawMessage{"future": json.RawMessage(`[1]`)},
+	}
+
+	tests := []struct {
+		fields []string
+		want   string
+		err    string
+	}{{
+		fields: []string{"name", "priority", "loads", "future"},
+		want:   `{"future":[1],"loads":null,"name":"Order 1","priority":0}`,
+	}, {
+		fields: []string{"pickup.notes", "pickup.location.address", "pickup.location.latLng"},
+		want:   `{"pickup":{"location":{"address":"a","latLng":null},"notes":"n"}}`,
+	}, {
+		fields: []string{"pickup"},
+		want:   `{"pickup":{"location":{"address":"a"},"notes":"n"}}`,
+	}, {
+		// Fields of an empty step are empty.
+		fields: []string{"delivery.notes", "delivery.serviceTimeSec"},
+		want:   `{"delivery":{"notes":"","serviceTimeSec":0}}`,
+	}, {
+		fields: []string{"delivery"},
+		want:   `{"delivery":null}`,
+	}, {
+		fields: []string{"pickup.nope"},
+		err:    `invalid field "pickup.nope": unknown field nope`,
+	}, {
+		// Structs without an Extra field have no unknown members.
+		fields: []string{"eligibility.byDate.month"},
+		err:    `invalid field "eligibility.byDate.month": unknown field month`,
+	}, {
+		// Dates are encoded as strings, not as their Go fields.
+		fields: []string{"eligibility.byDate.Year"},
+		err:    `invalid field "eligibility.byDate.Year": unknown field Year`,
+	}, {
+		fields: []string{"eligibility.byDate"},
+		want:   `{"eligibility":{"byDate":null}}`,
+	}, {
+		fields: []string{"name.first"},
+		err:    `invalid field "name.first": name is not an object`,
+	}, {
+		fields: []string{"pickup", "pickup.notes"},
+		err:    `invalid field "pickup.notes": pickup is selected as a whole`,
+	}, {
+		fields: []string{"name", "name"},
+		err:    `invalid field "name": selected more than once`,
+	}}
+
+	for _, test := range tests {
+		c := qt.New(t)
+		p, err := patch(o, test.fields)
+		if test.err != "" {
+			c.Check(err, qt.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, qt.IsNil)
+		b, err := json.Marshal(p)
+		c.Assert(err, qt.IsNil)
+		c.Check(string(b), qt.Equals, test.want, qt.Commentf("%v", test.fields))
+	}
+}
@@ -0,0 +1,144 @@
+package workwave
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+const defaultPlanChunkSize = 100
+
+// RoutePlan is a Route whose steps are joined with the orders they serve, and
+// with its driver and vehicle.
+type RoutePlan struct {
+	Route Route
+	// Driver and Vehicle are nil if they were not given to PlanRoutes.
+	Driver  *Driver
+	Vehicle *Vehicle
+	// Steps has an entry for each of the route's steps, in the same order.
+	Steps []PlanStep
+}
+
+// PlanStep is a RouteStep joined with the order it serves. Order and
+// OrderStep are nil for steps which serve no order, such as departures, and
+// for steps whose order no longer exists.
+type PlanStep struct {
+	RouteStep
+	Order *Order
+	// OrderStep is the pickup or delivery of Order served by the step.
+	OrderStep *OrderStep
+}
+
+// planStepJSON is a PlanStep without the JSON methods promoted from RouteStep,
+// which would encode the route step alone.
+type planStepJSON struct {
+	routeStepJSON
+	Order     *Order
+	OrderStep *OrderStep
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s PlanStep) MarshalJSON() ([]byte, error) {
+	return marshalObject(planStepJSON{routeStepJSON(s.RouteStep), s.Order, s.OrderStep}, s.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *PlanStep) UnmarshalJSON(b []byte) error {
+	var v planStepJSON
+	if err := unmarshalObject(b, &v, &v.Extra); err != nil {
+		return err
+	}
+	*s = PlanStep{RouteStep(v.routeStepJSON), v.Order, v.OrderStep}
+	return nil
+}
+
+// StepFor returns the pickup of o for StepPickup, and its delivery for
+// StepDelivery. It returns nil for other step types, or if o has no such step.
+func (o *Order) StepFor(t StepType) *OrderStep {
+	switch t {
+	case StepPickup:
+		return o.Pickup
+	case StepDelivery:
+		return o.Delivery
+	}
+	return nil
+}
+
+// PlanRoutesInput is used to populate a call to PlanRoutes.
+type PlanRoutesInput struct {
+	TerritoryID string
+	// Routes are typically returned by RoutesService.ListCurrent or
+	// ListApproved.
+	Routes []Route
+	// Drivers and Vehicles are optional, and are matched to the routes by ID.
+	Drivers  []Driver
+	Vehicles []Vehicle
+	// ChunkSize is the maximum number of orders fetched per request.
+	// Defaults to 100.
+	ChunkSize int
+}
+
+// PlanRoutes joins routes with the orders they serve, which are fetched with
+// OrdersService.Get. Each order is fetched once, however many steps serve it,
+// in chunks of at most ChunkSize orders. The returned plans are in the same
+// order as the input routes.
+func PlanRoutes(ctx context.Context, svc OrdersService, i PlanRoutesInput) ([]RoutePlan, error) {
+	size := i.ChunkSize
+	if size <= 0 {
+		size = defaultPlanChunkSize
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, r := range i.Routes {
+		for _, s := range r.Steps {
+			if s.OrderID != "" && !seen[s.OrderID] {
+				seen[s.OrderID] = true
+				ids = append(ids, s.OrderID)
+			}
+		}
+	}
+
+	orders := make(map[string]*Order, len(ids))
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk, err := svc.Get(ctx, OrdersGetInput{TerritoryID: i.TerritoryID, IDs: ids[start:end]})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get route orders")
+		}
+		for n := range chunk {
+			orders[chunk[n].ID] = &chunk[n]
+		}
+	}
+
+	drivers := make(map[string]*Driver, len(i.Drivers))
+	for n := range i.Drivers {
+		drivers[i.Drivers[n].ID] = &i.Drivers[n]
+	}
+	vehicles := make(map[string]*Vehicle, len(i.Vehicles))
+	for n := range i.Vehicles {
+		vehicles[i.Vehicles[n].ID] = &i.Vehicles[n]
+	}
+
+	plans := make([]RoutePlan, len(i.Routes))
+	for n, r := range i.Routes {
+		p := RoutePlan{
+			Route:   r,
+			Driver:  drivers[r.DriverID],
+			Vehicle: vehicles[r.VehicleID],
+			Steps:   make([]PlanStep, len(r.Steps)),
+		}
+		for m, s := range r.Steps {
+			p.Steps[m].RouteStep = s
+			if o, ok := orders[s.OrderID]; ok && s.OrderID != "" {
+				p.Steps[m].Order = o
+				p.Steps[m].OrderStep = o.StepFor(s.Type)
+			}
+		}
+		plans[n] = p
+	}
+	return plans, nil
+}
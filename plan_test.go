@@ -0,0 +1,142 @@
+package workwave
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPlanRoutes(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	b, err := ioutil.ReadFile(filepath.Join("testdata", "routes-list-approved.json"))
+	c.Assert(err, qt.IsNil)
+	var fixture struct {
+		Routes  map[string]Route  `json:"routes"`
+		Orders  map[string]Order  `json:"orders"`
+		Drivers map[string]Driver `json:"drivers"`
+	}
+	c.Assert(json.Unmarshal(b, &fixture), qt.IsNil)
+
+	var requested [][]string
+	mux.HandleFunc("/api/v1/territories/territory/orders", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		c.Check(json.NewDecoder(r.Body).Decode(&body), qt.IsNil)
+		requested = append(requested, body.IDs)
+
+		resp := ordersResponse{Orders: make(map[string]Order)}
+		for _, id := range body.IDs {
+			if o, ok := fixture.Orders[id]; ok {
+				resp.Orders[id] = o
+			}
+		}
+		c.Check(json.NewEncoder(w).Encode(resp), qt.IsNil)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	var routes []Route
+	for _, r := range fixture.Routes {
+		routes = append(routes, r)
+	}
+	sort.Slice(routes, func(a, b int) bool { return routes[a].ID < routes[b].ID })
+	// The same order served twice is only fetched once.
+	routes = append(routes, routes[0])
+	// Steps whose order no longer exists are kept.
+	routes[2].Steps = append(routes[2].Steps, RouteStep{Type: StepDelivery, OrderID: "deleted"})
+
+	plans, err := PlanRoutes(ctx, client.Orders, PlanRoutesInput{
+		TerritoryID: "territory",
+		Routes:      routes,
+		Drivers:     []Driver{fixture.Drivers["a08213e6-673f-4efc-955e-2bf587813162"]},
+		Vehicles:    []Vehicle{{ID: "31656f79-cba7-4bcf-a959-e3fe3f7ca2a7", Name: "Vehicle 2"}},
+		ChunkSize:   2,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(requested, qt.DeepEquals, [][]string{
+		{"49269a16-479c-4531-8ffd-513b7ccd0621", "65413bab-aea8-41af-8992-487a32f50a59"},
+		{"1066ecd2-8171-4daf-a8f3-9bf302a2a38f", "deleted"},
+	})
+	c.Assert(plans, qt.HasLen, 3)
+
+	p := plans[0]
+	c.Assert(p.Route.ID, qt.Equals, "0d8855e6-28a0-4e89-9c67-b44c66c39ba6-20151204")
+	c.Assert(p.Driver.Name, qt.Equals, "Driver 2")
+	c.Assert(p.Vehicle, qt.IsNil)
+	c.Assert(p.Steps, qt.HasLen, 4)
+	c.Assert(p.Steps[0].Type, qt.Equals, StepDeparture)
+	c.Assert(p.Steps[0].Order, qt.IsNil)
+	c.Assert(p.Steps[1].Order.Name, qt.Equals, "Order 1")
+	c.Assert(p.Steps[1].OrderStep, qt.Equals, p.Steps[1].Order.Delivery)
+	c.Assert(p.Steps[1].ArrivalSec, qt.Equals, SecOfDay(32689))
+
+	p = plans[1]
+	c.Assert(p.Driver, qt.IsNil)
+	c.Assert(p.Vehicle.Name, qt.Equals, "Vehicle 2")
+	c.Assert(p.Steps[1].Order.Name, qt.Equals, "Order 6")
+	c.Assert(p.Steps[1].OrderStep, qt.Equals, p.Steps[1].Order.Pickup)
+
+	p = plans[2]
+	c.Assert(p.Steps, qt.HasLen, 5)
+	c.Assert(p.Steps[1].Order, qt.Equals, plans[0].Steps[1].Order)
+	c.Assert(p.Steps[4].OrderID, qt.Equals, "deleted")
+	c.Assert(p.Steps[4].Order, qt.IsNil)
+}
+
+func TestPlanRoutesError(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	_, err := PlanRoutes(ctx, client.Orders, PlanRoutesInput{
+		TerritoryID: "territory",
+		Routes:      []Route{{Steps: []RouteStep{{Type: StepDelivery, OrderID: "o1"}}}},
+	})
+	c.Assert(err, qt.ErrorMatches, "failed to get route orders: .*")
+}
+
+func TestOrderStepFor(t *testing.T) {
+	c := qt.New(t)
+	o := &Order{Pickup: &OrderStep{Notes: "pickup"}, Delivery: &OrderStep{Notes: "delivery"}}
+	c.Assert(o.StepFor(StepPickup), qt.Equals, o.Pickup)
+	c.Assert(o.StepFor(StepDelivery), qt.Equals, o.Delivery)
+	c.Assert(o.StepFor(StepArrival), qt.IsNil)
+}
+
+func TestPlanStepJSON(t *testing.T) {
+	c := qt.New(t)
+	o := &Order{ID: "o1", Name: "Order 1", Delivery: &OrderStep{Notes: "n"}}
+	s := PlanStep{
+		RouteStep: RouteStep{Type: StepDelivery, OrderID: "o1", Extra: map[string]json.RawMessage{"future": json.RawMessage(`1`)}},
+		Order:     o,
+		OrderStep: o.Delivery,
+	}
+
+	b, err := json.Marshal(s)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"type":"delivery","orderId":"o1",`+
+		`"Order":{"id":"o1","name":"Order 1","delivery":{"location":{},"notes":"n"}},`+
+		`"OrderStep":{"location":{},"notes":"n"},"future":1}`)
+
+	var got PlanStep
+	c.Assert(json.Unmarshal(b, &got), qt.IsNil)
+	c.Assert(got, qt.DeepEquals, s)
+}
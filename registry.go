@@ -0,0 +1,350 @@
+package workwave
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldType is the type of the values of a custom field. Custom field values
+// are strings in the API, which are formatted according to their type.
+type FieldType int
+
+// Custom field types.
+const (
+	FieldString FieldType = iota
+	FieldInt              // formatted by strconv.Itoa
+	FieldBool             // formatted by strconv.FormatBool
+	FieldDate             // formatted as yyyyMMdd
+	FieldEnum             // one of CustomField.Values
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldString:
+		return "string"
+	case FieldInt:
+		return "int"
+	case FieldBool:
+		return "bool"
+	case FieldDate:
+		return "date"
+	case FieldEnum:
+		return "enum"
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+// CustomField declares a custom field of order steps.
+type CustomField struct {
+	Name   string
+	Type   FieldType
+	Values []string // allowed values of FieldEnum fields
+}
+
+// check returns a problem with v as a value of f, if any.
+func (f CustomField) check(v string) string {
+	var err error
+	switch f.Type {
+	case FieldInt:
+		_, err = strconv.Atoi(v)
+	case FieldBool:
+		_, err = strconv.ParseBool(v)
+	case FieldDate:
+		_, err = ParseDate(v)
+	case FieldEnum:
+		for _, allowed := range f.Values {
+			if v == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q must be one of %s", v, strings.Join(f.Values, ", "))
+	}
+	if err != nil {
+		return fmt.Sprintf("%q is not a valid %s", v, f.Type)
+	}
+	return ""
+}
+
+// Registry declares the tags and custom fields used by the orders of a
+// territory, so that orders can be checked for misspelt tags and badly
+// formatted custom fields before they are submitted, and so that custom fields
+// are read and written according to their types. The zero value is an empty
+// Registry. A Registry must not be modified while it is used.
+type Registry struct {
+	// Strict makes tags and custom fields which are not declared errors.
+	// Otherwise only the values of declared custom fields are checked.
+	Strict bool
+
+	tags   map[string]bool
+	fields map[string]CustomField
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		tags:   make(map[string]bool),
+		fields: make(map[string]CustomField),
+	}
+}
+
+// DeclareTags declares the given tags.
+func (r *Registry) DeclareTags(tags ...string) error {
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("tag is empty")
+		}
+		if r.tags == nil {
+			r.tags = make(map[string]bool)
+		}
+		r.tags[tag] = true
+	}
+	return nil
+}
+
+// DeclareField declares a custom field, which must not already be declared.
+func (r *Registry) DeclareField(f CustomField) error {
+	switch {
+	case strings.TrimSpace(f.Name) == "":
+		return errors.New("custom field name is empty")
+	case r.fields[f.Name].Name != "":
+		return errors.Errorf("custom field %q is already declared", f.Name)
+	case f.Type < FieldString || f.Type > FieldEnum:
+		return errors.Errorf("custom field %q has an invalid type %v", f.Name, f.Type)
+	case f.Type == FieldEnum && len(f.Values) == 0:
+		return errors.Errorf("custom field %q has no values", f.Name)
+	case f.Type != FieldEnum && len(f.Values) > 0:
+		return errors.Errorf("custom field %q is not an enum", f.Name)
+	}
+	if r.fields == nil {
+		r.fields = make(map[string]CustomField)
+	}
+	r.fields[f.Name] = f
+	return nil
+}
+
+// Tags returns the declared tags, sorted.
+func (r *Registry) Tags() []string {
+	tags := make([]string, 0, len(r.tags))
+	for tag := range r.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Field returns the declared custom field with the given name.
+func (r *Registry) Field(name string) (CustomField, bool) {
+	f, ok := r.fields[name]
+	return f, ok
+}
+
+// SetCustom sets a custom field of s to v, which is a string, int, bool or
+// Date, after checking it against the declared type of the field. Fields
+// which are not declared are errors when the registry is strict. The field is
+// removed for the zero Date.
+func (r *Registry) SetCustom(s *OrderStep, name string, v interface{}) error {
+	var value string
+	switch v := v.(type) {
+	case string:
+		value = v
+	case int:
+		value = strconv.Itoa(v)
+	case bool:
+		value = strconv.FormatBool(v)
+	case Date:
+		if v.IsZero() {
+			if err := r.declared(name, FieldDate); err != nil {
+				return err
+			}
+			s.DeleteCustom(name)
+			return nil
+		}
+		value = v.String()
+	default:
+		return errors.Errorf("custom field %q: unsupported value type %T", name, v)
+	}
+
+	f, ok := r.fields[name]
+	switch {
+	case ok:
+		if msg := f.check(value); msg != "" {
+			return errors.Errorf("custom field %q: %s", name, msg)
+		}
+	case r.Strict:
+		return errors.Errorf("custom field %q is not declared%s", name, suggest(name, r.fieldNames()))
+	}
+	if s.CustomFields == nil {
+		s.CustomFields = make(map[string]string)
+	}
+	s.CustomFields[name] = value
+	return nil
+}
+
+// Validate checks the tags and custom fields of o against the registry. It
+// returns ValidationErrors, or nil if o is valid.
+func (r *Registry) Validate(o Order) error {
+	v := &orderValidator{index: -1}
+	r.order(v, o)
+	return v.err()
+}
+
+// ValidateOrders validates each of the given orders against the registry,
+// returning ValidationErrors indexed by their position in orders, or nil if
+// all orders are valid.
+func (r *Registry) ValidateOrders(orders []Order) error {
+	v := &orderValidator{}
+	for i, o := range orders {
+		v.index = i
+		r.order(v, o)
+	}
+	return v.err()
+}
+
+func (r *Registry) order(v *orderValidator, o Order) {
+	if o.Pickup != nil {
+		r.step(v, "pickup", *o.Pickup)
+	}
+	if o.Delivery != nil {
+		r.step(v, "delivery", *o.Delivery)
+	}
+}
+
+func (r *Registry) step(v *orderValidator, field string, s OrderStep) {
+	r.tagList(v, field+".tagsIn", s.TagsIn)
+	r.tagList(v, field+".tagsOut", s.TagsOut)
+
+	names := make([]string, 0, len(s.CustomFields))
+	for name := range s.CustomFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fmt.Sprintf("%s.customFields[%q]", field, name)
+		cf, ok := r.fields[name]
+		if !ok {
+			if r.Strict {
+				v.add(f, "is not a declared custom field%s", suggest(name, r.fieldNames()))
+			}
+			continue
+		}
+		if msg := cf.check(s.CustomFields[name]); msg != "" {
+			v.add(f, "%s", msg)
+		}
+	}
+}
+
+func (r *Registry) tagList(v *orderValidator, field string, tags []string) {
+	if !r.Strict {
+		return
+	}
+	for i, tag := range tags {
+		if !r.tags[tag] {
+			v.add(fmt.Sprintf("%s[%d]", field, i), "%q is not a declared tag%s", tag, suggest(tag, r.Tags()))
+		}
+	}
+}
+
+func (r *Registry) fieldNames() []string {
+	names := make([]string, 0, len(r.fields))
+	for name := range r.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// suggest returns a hint naming the declared spelling of s, if s only differs
+// from it by case, spaces, hyphens or underscores.
+func suggest(s string, declared []string) string {
+	key := spellingKey(s)
+	for _, d := range declared {
+		if spellingKey(d) == key {
+			return fmt.Sprintf(", did you mean %q?", d)
+		}
+	}
+	return ""
+}
+
+func spellingKey(s string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(s))
+}
+
+// CustomString returns the value of a string or enum custom field of s, or ""
+// if it is not set.
+func (r *Registry) CustomString(s OrderStep, name string) (string, error) {
+	v, _, err := r.custom(s, name, FieldString)
+	return v, err
+}
+
+// CustomInt returns the value of an int custom field of s, or 0 if it is not
+// set.
+func (r *Registry) CustomInt(s OrderStep, name string) (int, error) {
+	v, ok, err := r.custom(s, name, FieldInt)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return strconv.Atoi(v)
+}
+
+// CustomBool returns the value of a bool custom field of s, or false if it is
+// not set.
+func (r *Registry) CustomBool(s OrderStep, name string) (bool, error) {
+	v, ok, err := r.custom(s, name, FieldBool)
+	if err != nil || !ok {
+		return false, err
+	}
+	return strconv.ParseBool(v)
+}
+
+// CustomDate returns the value of a date custom field of s, or the zero Date
+// if it is not set.
+func (r *Registry) CustomDate(s OrderStep, name string) (Date, error) {
+	v, ok, err := r.custom(s, name, FieldDate)
+	if err != nil || !ok {
+		return Date{}, err
+	}
+	return ParseDate(v)
+}
+
+// custom returns the value of a custom field of s, which is read as type t,
+// and whether it is set. Fields which are not declared are read as t unless
+// the registry is strict.
+func (r *Registry) custom(s OrderStep, name string, t FieldType) (string, bool, error) {
+	if err := r.declared(name, t); err != nil {
+		return "", false, err
+	}
+	v, ok := s.CustomFields[name]
+	if !ok {
+		return "", false, nil
+	}
+	f, declared := r.fields[name]
+	if !declared {
+		f = CustomField{Name: name, Type: t}
+	}
+	if msg := f.check(v); msg != "" {
+		return "", false, errors.Errorf("custom field %q: %s", name, msg)
+	}
+	return v, true, nil
+}
+
+// declared checks that the custom field with the given name can be read or
+// written as type t, where enums are strings.
+func (r *Registry) declared(name string, t FieldType) error {
+	f, ok := r.fields[name]
+	switch {
+	case !ok && r.Strict:
+		return errors.Errorf("custom field %q is not declared%s", name, suggest(name, r.fieldNames()))
+	case ok && f.Type != t && !(t == FieldString && f.Type == FieldEnum):
+		return errors.Errorf("custom field %q is declared as %s, not %s", name, f.Type, t)
+	}
+	return nil
+}
+
+// DeleteCustom removes a custom field.
+func (s *OrderStep) DeleteCustom(name string) {
+	delete(s.CustomFields, name)
+}
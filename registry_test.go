@@ -0,0 +1,213 @@
+package workwave
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func testRegistry(c *qt.C) *Registry {
+	r := NewRegistry()
+	c.Assert(r.DeclareTags("Fridge", "hazmat"), qt.IsNil)
+	for _, f := range []CustomField{
+		{Name: "floor", Type: FieldInt},
+		{Name: "fragile", Type: FieldBool},
+		{Name: "due", Type: FieldDate},
+		{Name: "size", Type: FieldEnum, Values: []string{"S", "M", "L"}},
+		{Name: "contact", Type: FieldString},
+	} {
+		c.Assert(r.DeclareField(f), qt.IsNil)
+	}
+	return r
+}
+
+func TestRegistryDeclare(t *testing.T) {
+	c := qt.New(t)
+	r := testRegistry(c)
+
+	c.Assert(r.Tags(), qt.DeepEquals, []string{"Fridge", "hazmat"})
+	f, ok := r.Field("size")
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(f.Type, qt.Equals, FieldEnum)
+	c.Assert(f.Type.String(), qt.Equals, "enum")
+
+	c.Assert(r.DeclareTags(" "), qt.ErrorMatches, "tag is empty")
+	c.Assert(r.DeclareField(CustomField{}), qt.ErrorMatches, "custom field name is empty")
+	c.Assert(r.DeclareField(CustomField{Name: "floor"}), qt.ErrorMatches, `custom field "floor" is already declared`)
+	c.Assert(r.DeclareField(CustomField{Name: "x", Type: 9}), qt.ErrorMatches, `custom field "x" has an invalid type FieldType\(9\)`)
+	c.Assert(r.DeclareField(CustomField{Name: "x", Type: FieldEnum}), qt.ErrorMatches, `custom field "x" has no values`)
+	c.Assert(r.DeclareField(CustomField{Name: "x", Values: []string{"a"}}), qt.ErrorMatches, `custom field "x" is not an enum`)
+}
+
+func TestRegistryZero(t *testing.T) {
+	c := qt.New(t)
+	var r Registry
+	c.Assert(r.Tags(), qt.HasLen, 0)
+	c.Assert(r.Validate(Order{Delivery: &OrderStep{TagsIn: []string{"x"}}}), qt.IsNil)
+	c.Assert(r.DeclareTags("fridge"), qt.IsNil)
+	c.Assert(r.DeclareField(CustomField{Name: "floor", Type: FieldInt}), qt.IsNil)
+	c.Assert(r.Tags(), qt.DeepEquals, []string{"fridge"})
+}
+
+func TestRegistryCustomAccessors(t *testing.T) {
+	c := qt.New(t)
+	r := testRegistry(c)
+	var s OrderStep
+	d := NewDate(2019, 10, 19)
+
+	c.Assert(r.SetCustom(&s, "floor", 3), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "fragile", true), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "due", d), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "size", "M"), qt.IsNil)
+	c.Assert(s.CustomFields, qt.DeepEquals, map[string]string{
+		"floor": "3", "fragile": "true", "due": "20191019", "size": "M",
+	})
+
+	n, err := r.CustomInt(s, "floor")
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 3)
+	b, err := r.CustomBool(s, "fragile")
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.Equals, true)
+	got, err := r.CustomDate(s, "due")
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.Equals, d)
+	v, err := r.CustomString(s, "size")
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, "M")
+
+	// Unset fields are zero.
+	n, err = r.CustomInt(s, "missing")
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 0)
+
+	// Fields are read according to their declared type.
+	_, err = r.CustomInt(s, "size")
+	c.Assert(err, qt.ErrorMatches, `custom field "size" is declared as enum, not int`)
+	_, err = r.CustomDate(s, "fragile")
+	c.Assert(err, qt.ErrorMatches, `custom field "fragile" is declared as bool, not date`)
+	s.CustomFields["floor"] = "two"
+	s.CustomFields["level"] = "two"
+	_, err = r.CustomInt(s, "floor")
+	c.Assert(err, qt.ErrorMatches, `custom field "floor": "two" is not a valid int`)
+	_, err = r.CustomInt(s, "level")
+	c.Assert(err, qt.ErrorMatches, `custom field "level": "two" is not a valid int`)
+	s.CustomFields["size"] = "XL"
+	_, err = r.CustomString(s, "size")
+	c.Assert(err, qt.ErrorMatches, `custom field "size": "XL" must be one of S, M, L`)
+
+	r.Strict = true
+	_, err = r.CustomBool(s, "Fragile")
+	c.Assert(err, qt.ErrorMatches, `custom field "Fragile" is not declared, did you mean "fragile"\?`)
+
+	c.Assert(r.SetCustom(&s, "due", Date{}), qt.IsNil)
+	s.DeleteCustom("size")
+	c.Assert(s.CustomFields, qt.DeepEquals, map[string]string{"floor": "two", "fragile": "true", "level": "two"})
+}
+
+func TestRegistrySetCustom(t *testing.T) {
+	c := qt.New(t)
+	r := testRegistry(c)
+	var s OrderStep
+
+	c.Assert(r.SetCustom(&s, "floor", 2), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "due", NewDate(2019, 10, 19)), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "size", "L"), qt.IsNil)
+	c.Assert(r.SetCustom(&s, "notes2", "x"), qt.IsNil)
+	c.Assert(s.CustomFields, qt.DeepEquals, map[string]string{
+		"floor": "2", "due": "20191019", "size": "L", "notes2": "x",
+	})
+
+	c.Assert(r.SetCustom(&s, "floor", true), qt.ErrorMatches, `custom field "floor": "true" is not a valid int`)
+	c.Assert(r.SetCustom(&s, "size", "XL"), qt.ErrorMatches, `custom field "size": "XL" must be one of S, M, L`)
+	c.Assert(r.SetCustom(&s, "floor", 1.5), qt.ErrorMatches, `custom field "floor": unsupported value type float64`)
+
+	r.Strict = true
+	c.Assert(r.SetCustom(&s, "Floor", 1), qt.ErrorMatches, `custom field "Floor" is not declared, did you mean "floor"\?`)
+	c.Assert(s.CustomFields["floor"], qt.Equals, "2")
+}
+
+func TestRegistryValidate(t *testing.T) {
+	c := qt.New(t)
+	r := testRegistry(c)
+
+	o := Order{
+		Name: "o",
+		Pickup: &OrderStep{
+			TagsIn:       []string{"fridge", "Fridge"},
+			CustomFields: map[string]string{"floor": "two", "Due-Date": "20191019"},
+		},
+		Delivery: &OrderStep{
+			TagsOut:      []string{"haz mat", "lift"},
+			CustomFields: map[string]string{"size": "XL", "fragile": "yes", "due": "2019-10-19", "contact": "anything"},
+		},
+	}
+
+	// Only declared custom fields are checked unless strict.
+	c.Assert(r.Validate(o), qt.ErrorMatches, `pickup.customFields\["floor"\]: "two" is not a valid int; `+
+		`delivery.customFields\["due"\]: "2019-10-19" is not a valid date; `+
+		`delivery.customFields\["fragile"\]: "yes" is not a valid bool; `+
+		`delivery.customFields\["size"\]: "XL" must be one of S, M, L`)
+
+	r.Strict = true
+	err := r.ValidateOrders([]Order{{Name: "ok"}, o})
+	c.Assert(err, qt.ErrorMatches, `orders\[1\].pickup.tagsIn\[0\]: "fridge" is not a declared tag, did you mean "Fridge"\?; `+
+		`orders\[1\].pickup.customFields\["Due-Date"\]: is not a declared custom field; `+
+		`orders\[1\].pickup.customFields\["floor"\]: "two" is not a valid int; `+
+		`orders\[1\].delivery.tagsOut\[0\]: "haz mat" is not a declared tag, did you mean "hazmat"\?; `+
+		`orders\[1\].delivery.tagsOut\[1\]: "lift" is not a declared tag; `+
+		`orders\[1\].delivery.customFields\["due"\]: "2019-10-19" is not a valid date; `+
+		`orders\[1\].delivery.customFields\["fragile"\]: "yes" is not a valid bool; `+
+		`orders\[1\].delivery.customFields\["size"\]: "XL" must be one of S, M, L`)
+	verrs, ok := err.(ValidationErrors)
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(verrs.ByIndex()[1], qt.HasLen, 8)
+}
+
+func TestOrdersAddRegistry(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	called := false
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	reg := testRegistry(c)
+	reg.Strict = true
+	orders := []Order{{Name: "o", Delivery: &OrderStep{TagsIn: []string{"frige"}}}}
+	_, err := client.Orders.Add(ctx, OrdersAddInput{
+		TerritoryID: "territory",
+		Orders:      orders,
+		Registry:    reg,
+	})
+	c.Assert(err, qt.ErrorMatches, `orders\[0\].delivery.tagsIn\[0\]: "frige" is not a declared tag`)
+
+	_, err = client.Orders.(OrdersUpdater).Update(ctx, OrdersUpdateInput{
+		TerritoryID: "territory",
+		Order:       Order{ID: "o1", Delivery: &OrderStep{CustomFields: map[string]string{"floor": "x"}}},
+		Fields:      []string{"delivery.customFields"},
+		Registry:    reg,
+	})
+	c.Assert(err, qt.ErrorMatches, `delivery.customFields\["floor"\]: "x" is not a valid int`)
+
+	report, err := BulkAddOrders(ctx, &fakeOrders{}, BulkAddInput{
+		TerritoryID: "territory",
+		Orders:      append(orders, Order{Name: "ok"}),
+		Registry:    reg,
+		ChunkSize:   1,
+	})
+	c.Assert(err, qt.ErrorMatches, `orders\[0\].delivery.tagsIn\[0\]: "frige" is not a declared tag`)
+	c.Assert(report.Chunks, qt.HasLen, 2)
+	c.Assert(report.Orders, qt.HasLen, 2)
+	c.Assert(report.Orders[0].Status, qt.Equals, BulkPending)
+	c.Assert(report.Orders[0].Err, qt.ErrorMatches, `orders\[0\].delivery.tagsIn\[0\]: .*`)
+	c.Assert(report.Orders[1], qt.DeepEquals, BulkOrderResult{Chunk: 1, Status: BulkPending})
+	c.Assert(called, qt.Equals, false)
+}
@@ -2,8 +2,10 @@ package workwave
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -23,6 +25,23 @@ type RoutesService interface {
 	ListApproved(context.Context, RoutesListApprovedInput) ([]Route, error)
 }
 
+// The following optional interfaces are implemented by the RoutesService of a
+// Client, ie
+//
+//	it, err := client.Routes.(workwave.RoutesStreamer).ListCurrentStream(ctx, input)
+
+// RoutesMapLister lists routes by ID.
+type RoutesMapLister interface {
+	ListCurrentMap(context.Context, RoutesListCurrentInput) (map[string]Route, error)
+	ListApprovedMap(context.Context, RoutesListApprovedInput) (map[string]Route, error)
+}
+
+// RoutesStreamer lists routes as they are decoded.
+type RoutesStreamer interface {
+	ListCurrentStream(context.Context, RoutesListCurrentInput) (*RouteIterator, error)
+	ListApprovedStream(context.Context, RoutesListApprovedInput) (*RouteIterator, error)
+}
+
 type routesService struct {
 	client *Client
 }
@@ -32,28 +51,100 @@ type routesService struct {
 type Route struct {
 	ID        string      `json:"id,omitempty"`
 	Revision  int         `json:"revision,omitempty"`
-	Date      string      `json:"date,omitempty"` // in the format yyyyMMdd
+	Date      Date        `json:"date,omitempty"`
 	Steps     []RouteStep `json:"steps,omitempty"`
 	DriverID  string      `json:"driverId,omitempty"`
 	VehicleID string      `json:"vehicleId,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type routeJSON Route
+
+// MarshalJSON implements json.Marshaler. A zero Date is omitted, which
+// omitempty does not do for structs.
+func (r Route) MarshalJSON() ([]byte, error) {
+	v := struct {
+		routeJSON
+		Date *Date `json:"date,omitempty"`
+	}{routeJSON: routeJSON(r)}
+	if !r.Date.IsZero() {
+		v.Date = &r.Date
+	}
+	return marshalObject(v, r.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Route) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*routeJSON)(r), &r.Extra)
 }
 
 // RouteStep is one step along a delivery route and include departure,
 // a number of deliveries, and arrival.
 type RouteStep struct {
-	Type         string        `json:"type,omitempty"` // One of: departure, arrival, pickup. delivery, brk
+	Type         StepType      `json:"type,omitempty"`
 	OrderID      string        `json:"orderId,omitempty"`
-	ArrivalSec   int           `json:"arrivalSec,omitempty"`
-	StartSec     int           `json:"startSec,omitempty"`
-	EndSec       int           `json:"endSec,omitempty"`
+	ArrivalSec   SecOfDay      `json:"arrivalSec,omitempty"`
+	StartSec     SecOfDay      `json:"startSec,omitempty"`
+	EndSec       SecOfDay      `json:"endSec,omitempty"`
+	StopIdx      int           `json:"stopIdx,omitempty"` // steps at the same location share a stop
 	DisplayLabel string        `json:"displayLabel,omitempty"`
 	TrackingData *TrackingData `json:"trackingData,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type routeStepJSON RouteStep
+
+// MarshalJSON implements json.Marshaler.
+func (s RouteStep) MarshalJSON() ([]byte, error) {
+	return marshalObject(routeStepJSON(s), s.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *RouteStep) UnmarshalJSON(b []byte) error {
+	return unmarshalObject(b, (*routeStepJSON)(s), &s.Extra)
 }
 
 // TrackingData provides location, timing and status for a route step.
+// Times which are not known are -1, including those missing from decoded
+// JSON, and are encoded as -1 too rather than being omitted.
 type TrackingData struct {
-	Status    string `json:"status,omitempty"`
-	StatusSec int    `json:"statusSec,omitempty"`
+	DriverID  string `json:"driverId,omitempty"`
+	VehicleID string `json:"vehicleId,omitempty"`
+	// TimeIn and TimeOut are reported by the driver.
+	TimeInSec     SecOfDay `json:"timeInSec"`
+	TimeInLatLng  *LatLng  `json:"timeInLatLng,omitempty"`
+	TimeOutSec    SecOfDay `json:"timeOutSec"`
+	TimeOutLatLng *LatLng  `json:"timeOutLatLng,omitempty"`
+	// Status is the outcome of the step, set by the driver at StatusSec.
+	Status       TrackingStatus `json:"status,omitempty"`
+	StatusSec    SecOfDay       `json:"statusSec"`
+	StatusLatLng *LatLng        `json:"statusLatLng,omitempty"`
+	// TimeInDetected and TimeOutDetected are detected from GPS positions.
+	TimeInDetectedSec     SecOfDay `json:"timeInDetectedSec"`
+	TimeInDetectedLatLng  *LatLng  `json:"timeInDetectedLatLng,omitempty"`
+	TimeOutDetectedSec    SecOfDay `json:"timeOutDetectedSec"`
+	TimeOutDetectedLatLng *LatLng  `json:"timeOutDetectedLatLng,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"` // unknown fields, see WithStrictDecoding
+}
+
+type trackingDataJSON TrackingData
+
+// MarshalJSON implements json.Marshaler.
+func (d TrackingData) MarshalJSON() ([]byte, error) {
+	return marshalObject(trackingDataJSON(d), d.Extra)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *TrackingData) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	d.TimeInSec, d.TimeOutSec, d.StatusSec = -1, -1, -1
+	d.TimeInDetectedSec, d.TimeOutDetectedSec = -1, -1
+	return unmarshalObject(b, (*trackingDataJSON)(d), &d.Extra)
 }
 
 // RoutesListCurrentInput is used to populate a call to List Current Routes on the
@@ -64,13 +155,41 @@ type RoutesListCurrentInput struct {
 	Vehicle     string `json:"vehicle"`
 }
 
-type routesListResponse struct {
-	Routes map[string]Route `json:"routes"`
-}
-
 // ListCurrent lists current, live Routes, optionally filtering by date
-// and/or vehicleId.
+// and/or vehicleId. Routes are returned in the order of the API response.
 func (svc *routesService) ListCurrent(ctx context.Context, i RoutesListCurrentInput) ([]Route, error) {
+	it, err := svc.ListCurrentStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.all()
+}
+
+// ListCurrentMap lists the same routes as ListCurrent, by ID.
+func (svc *routesService) ListCurrentMap(ctx context.Context, i RoutesListCurrentInput) (map[string]Route, error) {
+	it, err := svc.ListCurrentStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.byID()
+}
+
+// ListCurrentStream lists the same routes as ListCurrent, decoding them one
+// at a time as they are read from the response.
+func (svc *routesService) ListCurrentStream(ctx context.Context, i RoutesListCurrentInput) (*RouteIterator, error) {
+	req, err := svc.listCurrentRequest(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := svc.client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &RouteIterator{s: newObjectStream(res.Body, "routes", svc.client.checkDecoded)}, nil
+}
+
+func (svc *routesService) listCurrentRequest(ctx context.Context, i RoutesListCurrentInput) (*http.Request, error) {
 	u := fmt.Sprintf(toaRoutesPath, i.TerritoryID)
 	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -85,18 +204,9 @@ func (svc *routesService) ListCurrent(ctx context.Context, i RoutesListCurrentIn
 	if i.Vehicle != "" {
 		q.Add("vehicle", i.Vehicle)
 	}
+	req.URL.RawQuery = q.Encode()
 
-	rlr := &routesListResponse{}
-	if _, err := svc.client.Do(ctx, req, rlr); err != nil {
-		return nil, err
-	}
-
-	var routes []Route
-	for _, route := range rlr.Routes {
-		routes = append(routes, route)
-	}
-
-	return routes, nil
+	return req, nil
 }
 
 // RoutesListApprovedInput
@@ -105,8 +215,41 @@ type RoutesListApprovedInput struct {
 	Date        string `json:"date"`
 }
 
-// ListApproved lists approved planned routes.
+// ListApproved lists approved planned routes, in the order of the API
+// response.
 func (svc *routesService) ListApproved(ctx context.Context, i RoutesListApprovedInput) ([]Route, error) {
+	it, err := svc.ListApprovedStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.all()
+}
+
+// ListApprovedMap lists the same routes as ListApproved, by ID.
+func (svc *routesService) ListApprovedMap(ctx context.Context, i RoutesListApprovedInput) (map[string]Route, error) {
+	it, err := svc.ListApprovedStream(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	return it.byID()
+}
+
+// ListApprovedStream lists the same routes as ListApproved, decoding them
+// one at a time as they are read from the response.
+func (svc *routesService) ListApprovedStream(ctx context.Context, i RoutesListApprovedInput) (*RouteIterator, error) {
+	req, err := svc.listApprovedRequest(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := svc.client.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &RouteIterator{s: newObjectStream(res.Body, "routes", svc.client.checkDecoded)}, nil
+}
+
+func (svc *routesService) listApprovedRequest(ctx context.Context, i RoutesListApprovedInput) (*http.Request, error) {
 	u := fmt.Sprintf(approvedRoutesPath, i.TerritoryID)
 	req, err := svc.client.NewRequest(ctx, http.MethodGet, u, nil)
 	if err != nil {
@@ -118,16 +261,21 @@ func (svc *routesService) ListApproved(ctx context.Context, i RoutesListApproved
 	if i.Date != "" {
 		q.Add("date", i.Date)
 	}
+	req.URL.RawQuery = q.Encode()
 
-	rlr := &routesListResponse{}
-	if _, err := svc.client.Do(ctx, req, rlr); err != nil {
-		return nil, err
-	}
-
-	var routes []Route
-	for _, route := range rlr.Routes {
-		routes = append(routes, route)
-	}
+	return req, nil
+}
 
-	return routes, nil
+// SortRoutes sorts routes by date, then vehicle, then ID.
+func SortRoutes(routes []Route) {
+	sort.SliceStable(routes, func(a, b int) bool {
+		ra, rb := routes[a], routes[b]
+		if ra.Date != rb.Date {
+			return ra.Date.Before(rb.Date)
+		}
+		if ra.VehicleID != rb.VehicleID {
+			return ra.VehicleID < rb.VehicleID
+		}
+		return ra.ID < rb.ID
+	})
 }
@@ -1,6 +1,7 @@
 package workwave
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -15,6 +16,7 @@ func TestRoutesListCurrent(t *testing.T) {
 	c := qt.New(t)
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.RawQuery, qt.Equals, "date=20191019&vehicle=vehicle")
 		http.ServeFile(w, r, filepath.Join("testdata", "routes-list-current.json"))
 	})
 
@@ -27,7 +29,19 @@ func TestRoutesListCurrent(t *testing.T) {
 		Vehicle:     "vehicle",
 	})
 	c.Assert(err, qt.IsNil)
-	c.Assert(len(o), qt.Equals, 2)
+	c.Assert(routeIDs(o), qt.DeepEquals, []string{
+		"0d8855e6-28a0-4e89-9c67-b44c66c39ba6-20151204",
+		"31656f79-cba7-4bcf-a959-e3fe3f7ca2a7-20151203",
+	})
+
+	m, err := client.Routes.(RoutesMapLister).ListCurrentMap(ctx, RoutesListCurrentInput{
+		TerritoryID: "territory",
+		Date:        "20191019",
+		Vehicle:     "vehicle",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 2)
+	c.Assert(m[o[1].ID], qt.DeepEquals, o[1])
 }
 
 func TestRoutesListApproved(t *testing.T) {
@@ -36,6 +50,7 @@ func TestRoutesListApproved(t *testing.T) {
 	c := qt.New(t)
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.RawQuery, qt.Equals, "date=20191019")
 		http.ServeFile(w, r, filepath.Join("testdata", "routes-list-approved.json"))
 	})
 
@@ -47,5 +62,60 @@ func TestRoutesListApproved(t *testing.T) {
 		Date:        "20191019",
 	})
 	c.Assert(err, qt.IsNil)
-	c.Assert(len(o), qt.Equals, 2)
+	c.Assert(routeIDs(o), qt.DeepEquals, []string{
+		"31656f79-cba7-4bcf-a959-e3fe3f7ca2a7-20151204",
+		"0d8855e6-28a0-4e89-9c67-b44c66c39ba6-20151204",
+	})
+
+	m, err := client.Routes.(RoutesMapLister).ListApprovedMap(ctx, RoutesListApprovedInput{
+		TerritoryID: "territory",
+		Date:        "20191019",
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(m, qt.HasLen, 2)
+	c.Assert(m[o[0].ID], qt.DeepEquals, o[0])
+}
+
+func routeIDs(routes []Route) []string {
+	var ids []string
+	for _, r := range routes {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSortRoutes(t *testing.T) {
+	c := qt.New(t)
+	d := Date{Year: 2015, Month: 12, Day: 4}
+	routes := []Route{
+		{ID: "4", Date: d, VehicleID: "b"},
+		{ID: "3", Date: d, VehicleID: "a"},
+		{ID: "2", Date: d, VehicleID: "a"},
+		{ID: "1", Date: d.AddDays(1), VehicleID: "a"},
+		{ID: "5", Date: d.AddDays(-1), VehicleID: "c"},
+	}
+	SortRoutes(routes)
+	c.Assert(routeIDs(routes), qt.DeepEquals, []string{"5", "2", "3", "4", "1"})
+}
+
+func TestTrackingDataJSON(t *testing.T) {
+	c := qt.New(t)
+
+	// Times missing from the JSON are not known, rather than midnight.
+	var step RouteStep
+	err := json.Unmarshal([]byte(`{"type": "delivery", "trackingData": {"timeInDetectedSec": 0, "status": "done"}}`), &step)
+	c.Assert(err, qt.IsNil)
+	td := step.TrackingData
+	c.Assert(td.TimeInSec.IsSet(), qt.Equals, false)
+	c.Assert(td.TimeOutSec.IsSet(), qt.Equals, false)
+	c.Assert(td.StatusSec.IsSet(), qt.Equals, false)
+	c.Assert(td.TimeOutDetectedSec.IsSet(), qt.Equals, false)
+	c.Assert(td.TimeInDetectedSec, qt.Equals, SecOfDay(0))
+	c.Assert(td.TimeInDetectedSec.IsSet(), qt.Equals, true)
+
+	// Midnight is encoded.
+	b, err := json.Marshal(td)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"timeInSec":-1,"timeOutSec":-1,"status":"done","statusSec":-1,`+
+		`"timeInDetectedSec":0,"timeOutDetectedSec":-1}`)
 }
@@ -0,0 +1,301 @@
+package workwave
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// objectStream decodes the members of the JSON object found under a key of
+// the response body, such as the orders of {"orders": {"id": {...}}}, one
+// at a time. Other members of the response are skipped token by token, so
+// that memory use does not depend on the size of the response.
+type objectStream struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+	key  string
+	// check is called with each decoded member.
+	check func(interface{}) error
+
+	started bool
+	done    bool
+	err     error
+}
+
+func newObjectStream(body io.ReadCloser, key string, check func(interface{}) error) *objectStream {
+	return &objectStream{body: body, dec: json.NewDecoder(body), key: key, check: check}
+}
+
+// next decodes the next member into v, returning its key. It returns false
+// once there are no more members, or an error occurred.
+func (s *objectStream) next(v interface{}) (string, bool) {
+	if s.done {
+		return "", false
+	}
+	if !s.started {
+		s.started = true
+		found, err := s.seek()
+		if err != nil || !found {
+			s.finish(err)
+			return "", false
+		}
+	}
+
+	if !s.dec.More() {
+		// Consume the closing brace of the object.
+		_, err := s.dec.Token()
+		s.finish(err)
+		return "", false
+	}
+	t, err := s.dec.Token()
+	if err != nil {
+		s.finish(err)
+		return "", false
+	}
+	key, _ := t.(string)
+	if err := s.dec.Decode(v); err != nil {
+		s.finish(err)
+		return "", false
+	}
+	if s.check != nil {
+		if err := s.check(v); err != nil {
+			s.finish(err)
+			return "", false
+		}
+	}
+	return key, true
+}
+
+// seek advances to the start of the object under the key, reporting whether
+// it was found.
+func (s *objectStream) seek() (bool, error) {
+	if err := s.expect(json.Delim('{')); err != nil {
+		return false, err
+	}
+	for s.dec.More() {
+		t, err := s.dec.Token()
+		if err != nil {
+			return false, err
+		}
+		if t != s.key {
+			if err := s.skip(); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		t, err = s.dec.Token()
+		switch {
+		case err != nil:
+			return false, err
+		case t == nil:
+			return false, nil
+		case t != json.Delim('{'):
+			return false, errors.Errorf("expected an object of %s, got %v", s.key, t)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *objectStream) expect(d json.Delim) error {
+	t, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return errors.Errorf("expected %v, got %v", d, t)
+	}
+	return nil
+}
+
+// skip consumes the next value without decoding it.
+func (s *objectStream) skip() error {
+	depth := 0
+	for {
+		t, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *objectStream) finish(err error) {
+	s.done = true
+	if err != nil && err != io.EOF {
+		s.err = errors.Wrap(err, "failed to decode JSON")
+	}
+	if cerr := s.body.Close(); s.err == nil && cerr != nil {
+		s.err = cerr
+	}
+}
+
+// close stops the stream early, releasing the response.
+func (s *objectStream) close() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	return s.body.Close()
+}
+
+// OrderIterator iterates over orders as they are decoded from a response,
+// rather than after the whole response has been decoded:
+//
+//	it, err := client.Orders.(workwave.OrdersStreamer).ListStream(ctx, input)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		o := it.Order()
+//		...
+//	}
+//	return it.Err()
+type OrderIterator struct {
+	s     *objectStream
+	order Order
+}
+
+// Next advances to the next order, returning false once there are no more
+// orders or an error occurred.
+func (it *OrderIterator) Next() bool {
+	it.order = Order{}
+	id, ok := it.s.next(&it.order)
+	if ok && it.order.ID == "" {
+		it.order.ID = id
+	}
+	return ok
+}
+
+// Order returns the current order.
+func (it *OrderIterator) Order() Order {
+	return it.order
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *OrderIterator) Err() error {
+	return it.s.err
+}
+
+// Close releases the response. It only needs to be called when stopping
+// before Next returns false.
+func (it *OrderIterator) Close() error {
+	return it.s.close()
+}
+
+// Each calls fn with each order until there are no more orders or fn returns
+// an error, and closes the iterator.
+func (it *OrderIterator) Each(fn func(Order) error) error {
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Order()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (it *OrderIterator) all() ([]Order, error) {
+	var orders []Order
+	err := it.Each(func(o Order) error {
+		orders = append(orders, o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (it *OrderIterator) byID() (map[string]Order, error) {
+	orders := make(map[string]Order)
+	err := it.Each(func(o Order) error {
+		orders[o.ID] = o
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// RouteIterator iterates over routes as they are decoded from a response. It
+// is used like an OrderIterator.
+type RouteIterator struct {
+	s     *objectStream
+	route Route
+}
+
+// Next advances to the next route, returning false once there are no more
+// routes or an error occurred.
+func (it *RouteIterator) Next() bool {
+	it.route = Route{}
+	id, ok := it.s.next(&it.route)
+	if ok && it.route.ID == "" {
+		it.route.ID = id
+	}
+	return ok
+}
+
+// Route returns the current route.
+func (it *RouteIterator) Route() Route {
+	return it.route
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *RouteIterator) Err() error {
+	return it.s.err
+}
+
+// Close releases the response. It only needs to be called when stopping
+// before Next returns false.
+func (it *RouteIterator) Close() error {
+	return it.s.close()
+}
+
+// Each calls fn with each route until there are no more routes or fn returns
+// an error, and closes the iterator.
+func (it *RouteIterator) Each(fn func(Route) error) error {
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Route()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (it *RouteIterator) all() ([]Route, error) {
+	var routes []Route
+	err := it.Each(func(r Route) error {
+		routes = append(routes, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (it *RouteIterator) byID() (map[string]Route, error) {
+	routes := make(map[string]Route)
+	err := it.Each(func(r Route) error {
+		routes[r.ID] = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
@@ -0,0 +1,184 @@
+package workwave
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestOrdersListStream(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.RawQuery, qt.Equals, "assignedOn=20191018&eligibleOn=20191019&include=assigned")
+		http.ServeFile(w, r, filepath.Join("testdata", "orders-list.json"))
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	i := OrdersListInput{
+		TerritoryID: "territory",
+		Include:     "assigned",
+		EligibleOn:  "20191019",
+		AssignedOn:  "20191018",
+	}
+	want, err := client.Orders.List(ctx, i)
+	c.Assert(err, qt.IsNil)
+
+	it, err := client.Orders.(OrdersStreamer).ListStream(ctx, i)
+	c.Assert(err, qt.IsNil)
+	defer it.Close()
+	var got []Order
+	for it.Next() {
+		got = append(got, it.Order())
+	}
+	c.Assert(it.Err(), qt.IsNil)
+
+	c.Assert(got, qt.DeepEquals, want)
+}
+
+func TestRoutesListStream(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/api/v1/territories/territory/toa/routes", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.RawQuery, qt.Equals, "date=20191019&vehicle=vehicle")
+		http.ServeFile(w, r, filepath.Join("testdata", "routes-list-current.json"))
+	})
+	mux.HandleFunc("/api/v1/territories/territory/approved/routes", func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.URL.RawQuery, qt.Equals, "date=20191019")
+		http.ServeFile(w, r, filepath.Join("testdata", "routes-list-approved.json"))
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	it, err := client.Routes.(RoutesStreamer).ListCurrentStream(ctx, RoutesListCurrentInput{
+		TerritoryID: "territory",
+		Date:        "20191019",
+		Vehicle:     "vehicle",
+	})
+	c.Assert(err, qt.IsNil)
+	var ids []string
+	err = it.Each(func(r Route) error {
+		c.Check(r.Steps, qt.Not(qt.HasLen), 0)
+		ids = append(ids, r.ID)
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(ids, qt.HasLen, 2)
+
+	it, err = client.Routes.(RoutesStreamer).ListApprovedStream(ctx, RoutesListApprovedInput{
+		TerritoryID: "territory",
+		Date:        "20191019",
+	})
+	c.Assert(err, qt.IsNil)
+	stop := errors.New("stop")
+	n := 0
+	err = it.Each(func(r Route) error {
+		n++
+		return stop
+	})
+	c.Assert(err, qt.Equals, stop)
+	c.Assert(n, qt.Equals, 1)
+	c.Assert(it.Next(), qt.Equals, false)
+}
+
+func TestListStreamHTTPError(t *testing.T) {
+	setup()
+	defer teardown()
+	c := qt.New(t)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	client, _ := New("api-key")
+	client.baseURL, _ = url.Parse(server.URL)
+
+	it, err := client.Orders.(OrdersStreamer).ListStream(ctx, OrdersListInput{TerritoryID: "territory"})
+	c.Assert(err, qt.ErrorMatches, "HTTP 401 error")
+	c.Assert(it, qt.IsNil)
+}
+
+type closeRecorder struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestObjectStream(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+		err  string
+	}{{
+		name: "skips other members",
+		body: `{"routes": {"r": {"steps": [{"a": [1, {"b": null}]}]}}, "n": 1, "orders": {"a": {"name": "A"}, "b": {"name": "B"}}, "depots": {}}`,
+		want: []string{"a:A", "b:B"},
+	}, {
+		name: "ID from key",
+		body: `{"orders": {"a": {"id": "x", "name": "A"}, "b": {"name": "B"}}}`,
+		want: []string{"x:A", "b:B"},
+	}, {
+		name: "empty",
+		body: `{"orders": {}}`,
+	}, {
+		name: "null",
+		body: `{"orders": null}`,
+	}, {
+		name: "missing",
+		body: `{"depots": {}}`,
+	}, {
+		name: "not an object",
+		body: `{"orders": []}`,
+		err:  `failed to decode JSON: expected an object of orders, got \[`,
+	}, {
+		name: "not a response",
+		body: `[]`,
+		err:  `failed to decode JSON: expected {, got \[`,
+	}, {
+		name: "truncated",
+		body: `{"orders": {"a": {"name": "A"}, "b": {"na`,
+		want: []string{"a:A"},
+		err:  "failed to decode JSON: unexpected EOF",
+	}, {
+		name: "invalid order",
+		body: `{"orders": {"a": {"name": 1}}}`,
+		err:  "failed to decode JSON: json: cannot unmarshal number .*",
+	}}
+
+	for _, test := range tests {
+		c := qt.New(t)
+		c.Run(test.name, func(c *qt.C) {
+			body := &closeRecorder{Reader: strings.NewReader(test.body)}
+			it := &OrderIterator{s: newObjectStream(body, "orders", nil)}
+			var got []string
+			for it.Next() {
+				got = append(got, it.Order().ID+":"+it.Order().Name)
+			}
+			c.Assert(got, qt.DeepEquals, test.want)
+			if test.err == "" {
+				c.Assert(it.Err(), qt.IsNil)
+			} else {
+				c.Assert(it.Err(), qt.ErrorMatches, test.err)
+			}
+			c.Assert(body.closed, qt.Equals, true)
+			c.Assert(it.Next(), qt.Equals, false)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package workwave
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a problem with a single field of an Order.
+type ValidationError struct {
+	// Index is the position of the order in the validated batch, or -1 when
+	// a single order was validated.
+	Index int
+	// Field is the path of the field within the order, ie
+	// "delivery.timeWindows[1]". It is empty for problems with the order as
+	// a whole.
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	if e.Index >= 0 {
+		fmt.Fprintf(&b, "orders[%d]", e.Index)
+	}
+	if e.Field != "" {
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(e.Field)
+	}
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// ValidationErrors is a list of problems found while validating orders.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ByIndex groups the errors by the index of the order they relate to.
+func (e ValidationErrors) ByIndex() map[int]ValidationErrors {
+	m := make(map[int]ValidationErrors)
+	for _, err := range e {
+		m[err.Index] = append(m[err.Index], err)
+	}
+	return m
+}
+
+// Validate checks o against the rules WorkWave applies when adding orders, so
+// that problems which would fail a Strict OrdersAddInput can be found before
+// the orders are submitted. It returns ValidationErrors, or nil if o is valid.
+func (o Order) Validate() error {
+	v := &orderValidator{index: -1}
+	v.order(o)
+	return v.err()
+}
+
+// ValidateOrders validates each of the given orders, returning
+// ValidationErrors indexed by their position in orders, or nil if all orders
+// are valid.
+func ValidateOrders(orders []Order) error {
+	v := &orderValidator{}
+	for i, o := range orders {
+		v.index = i
+		v.order(o)
+	}
+	return v.err()
+}
+
+type orderValidator struct {
+	index int
+	errs  ValidationErrors
+}
+
+func (v *orderValidator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{
+		Index:   v.index,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *orderValidator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *orderValidator) order(o Order) {
+	if strings.TrimSpace(o.Name) == "" {
+		v.add("name", "is required")
+	}
+
+	v.eligibility(o.Eligibility)
+
+	for _, k := range o.Loads.Dimensions() {
+		switch {
+		case strings.TrimSpace(string(k)) == "":
+			v.add("loads", "has an empty key")
+		case o.Loads[k] < 0:
+			v.add(fmt.Sprintf("loads[%q]", k), "must not be negative")
+		}
+	}
+
+	switch {
+	case o.Pickup == nil && o.Delivery == nil:
+		v.add("", "must have a pickup or a delivery")
+	case o.IsService && o.Pickup != nil && o.Delivery != nil:
+		v.add("isService", "service orders must have either a pickup or a delivery, not both")
+	}
+	if o.Pickup != nil {
+		v.step("pickup", *o.Pickup)
+	}
+	if o.Delivery != nil {
+		v.step("delivery", *o.Delivery)
+	}
+}
+
+func (v *orderValidator) eligibility(e Eligibility) {
+	switch {
+	case e.Type == "":
+		v.add("eligibility.type", "is required")
+	case !e.Type.Valid():
+		v.add("eligibility.type", "must be one of on, by, any")
+	}
+
+	switch {
+	case e.Type == EligibilityBy && e.ByDate == nil:
+		v.add("eligibility.byDate", "is required when type is by")
+	case e.Type != EligibilityBy && e.ByDate != nil:
+		v.add("eligibility.byDate", "is only allowed when type is by")
+	case e.ByDate != nil && e.ByDate.IsZero():
+		v.add("eligibility.byDate", "is not a valid date")
+	}
+
+	switch {
+	case e.Type == EligibilityOn && len(e.OnDates) == 0:
+		v.add("eligibility.onDates", "is required when type is on")
+	case e.Type != EligibilityOn && len(e.OnDates) > 0:
+		v.add("eligibility.onDates", "is only allowed when type is on")
+	}
+	seen := make(map[Date]bool, len(e.OnDates))
+	for i, d := range e.OnDates {
+		field := fmt.Sprintf("eligibility.onDates[%d]", i)
+		switch {
+		case d.IsZero():
+			v.add(field, "is not a valid date")
+		case seen[d]:
+			v.add(field, "duplicates %s", d)
+		}
+		seen[d] = true
+	}
+}
+
+func (v *orderValidator) step(field string, s OrderStep) {
+	hasLocation := s.Location.Address != "" || s.Location.LatLng != nil
+	switch {
+	case s.DepotID == "" && !hasLocation:
+		v.add(field+".location", "an address, latLng or depotId is required")
+	case s.DepotID != "" && hasLocation:
+		v.add(field+".depotId", "cannot be combined with a location")
+	}
+	if s.Location.LatLng != nil && !s.Location.LatLng.Valid() {
+		v.add(field+".location.latLng", "%v is out of range", *s.Location.LatLng)
+	}
+
+	if s.ServiceTimeSec < 0 {
+		v.add(field+".serviceTimeSec", "must not be negative")
+	}
+
+	v.timeWindows(field+".timeWindows", s.TimeWindows)
+	dates := make([]Date, 0, len(s.TimeWindowExceptions))
+	for d := range s.TimeWindowExceptions {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(a, b int) bool { return dates[a].Before(dates[b]) })
+	for _, d := range dates {
+		f := fmt.Sprintf("%s.timeWindowExceptions[%q]", field, d)
+		if d.IsZero() {
+			v.add(f, "is not a valid date")
+			continue
+		}
+		v.timeWindow(f, s.TimeWindowExceptions[d])
+	}
+
+	in := make(map[string]bool, len(s.TagsIn))
+	for i, tag := range s.TagsIn {
+		if strings.TrimSpace(tag) == "" {
+			v.add(fmt.Sprintf("%s.tagsIn[%d]", field, i), "is empty")
+		}
+		in[tag] = true
+	}
+	for i, tag := range s.TagsOut {
+		f := fmt.Sprintf("%s.tagsOut[%d]", field, i)
+		switch {
+		case strings.TrimSpace(tag) == "":
+			v.add(f, "is empty")
+		case in[tag]:
+			v.add(f, "tag %q is also in tagsIn", tag)
+		}
+	}
+}
+
+func (v *orderValidator) timeWindows(field string, tws []TimeWindow) {
+	for i, tw := range tws {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		v.timeWindow(f, tw)
+		if i > 0 && tw.StartSec < tws[i-1].EndSec {
+			v.add(f, "must start after the previous window ends at %s", tws[i-1].EndSec)
+		}
+	}
+}
+
+func (v *orderValidator) timeWindow(field string, tw TimeWindow) {
+	switch {
+	case tw.StartSec < 0:
+		v.add(field, "must not start before midnight")
+	case tw.EndSec <= tw.StartSec:
+		v.add(field, "must end after it starts at %s", tw.StartSec)
+	}
+}
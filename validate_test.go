@@ -0,0 +1,213 @@
+package workwave
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func validOrder() Order {
+	return Order{
+		Name: "Order 1",
+		Eligibility: Eligibility{
+			Type:    EligibilityOn,
+			OnDates: []Date{NewDate(2015, time.December, 4)},
+		},
+		Loads: Loads{"pallets": 2},
+		Delivery: &OrderStep{
+			Location: Location{Address: "3101-3199 Florida Ave, Jasper, AL 35501, USA"},
+			TimeWindows: []TimeWindow{
+				{StartSec: 30600, EndSec: 37800},
+				{StartSec: 45000, EndSec: 55800},
+			},
+			ServiceTimeSec: 600,
+		},
+	}
+}
+
+func TestOrderValidate(t *testing.T) {
+	byDate := NewDate(2015, time.December, 6)
+	for _, tt := range []struct {
+		name   string
+		modify func(o *Order)
+		want   string
+	}{
+		{
+			name:   "valid",
+			modify: func(o *Order) {},
+		},
+		{
+			name:   "missing name",
+			modify: func(o *Order) { o.Name = " " },
+			want:   "name: is required",
+		},
+		{
+			name:   "missing eligibility type",
+			modify: func(o *Order) { o.Eligibility = Eligibility{} },
+			want:   "eligibility.type: is required",
+		},
+		{
+			name: "by date with on type",
+			modify: func(o *Order) {
+				o.Eligibility.ByDate = &byDate
+			},
+			want: "eligibility.byDate: is only allowed when type is by",
+		},
+		{
+			name: "by type without date",
+			modify: func(o *Order) {
+				o.Eligibility = Eligibility{Type: EligibilityBy}
+			},
+			want: "eligibility.byDate: is required when type is by",
+		},
+		{
+			name: "on dates with any type",
+			modify: func(o *Order) {
+				o.Eligibility.Type = EligibilityAny
+			},
+			want: "eligibility.onDates: is only allowed when type is on",
+		},
+		{
+			name: "duplicate on dates",
+			modify: func(o *Order) {
+				o.Eligibility.OnDates = append(o.Eligibility.OnDates, o.Eligibility.OnDates[0])
+			},
+			want: "eligibility.onDates[1]: duplicates 20151204",
+		},
+		{
+			name:   "negative load",
+			modify: func(o *Order) { o.Loads["pallets"] = -1 },
+			want:   `loads["pallets"]: must not be negative`,
+		},
+		{
+			name:   "empty load key",
+			modify: func(o *Order) { o.Loads[""] = 1 },
+			want:   "loads: has an empty key",
+		},
+		{
+			name:   "no steps",
+			modify: func(o *Order) { o.Delivery = nil },
+			want:   "must have a pickup or a delivery",
+		},
+		{
+			name: "service with both steps",
+			modify: func(o *Order) {
+				o.IsService = true
+				o.Pickup = &OrderStep{DepotID: "depot"}
+			},
+			want: "isService: service orders must have either a pickup or a delivery, not both",
+		},
+		{
+			name:   "no location",
+			modify: func(o *Order) { o.Delivery.Location = Location{} },
+			want:   "delivery.location: an address, latLng or depotId is required",
+		},
+		{
+			name:   "location and depot",
+			modify: func(o *Order) { o.Delivery.DepotID = "depot" },
+			want:   "delivery.depotId: cannot be combined with a location",
+		},
+		{
+			name: "out of range latLng",
+			modify: func(o *Order) {
+				ll := NewLatLng(33817872, -87266893)
+				o.Delivery.Location.LatLng = &ll
+			},
+			want: "delivery.location.latLng: 33817872.000000,-87266893.000000 is out of range",
+		},
+		{
+			name:   "negative service time",
+			modify: func(o *Order) { o.Delivery.ServiceTimeSec = -60 },
+			want:   "delivery.serviceTimeSec: must not be negative",
+		},
+		{
+			name: "inverted time window",
+			modify: func(o *Order) {
+				o.Delivery.TimeWindows[0] = TimeWindow{StartSec: 37800, EndSec: 30600}
+			},
+			want: "delivery.timeWindows[0]: must end after it starts at 10:30",
+		},
+		{
+			name: "overlapping time windows",
+			modify: func(o *Order) {
+				o.Delivery.TimeWindows[1].StartSec = 36000
+			},
+			want: "delivery.timeWindows[1]: must start after the previous window ends at 10:30",
+		},
+		{
+			name: "invalid time window exception",
+			modify: func(o *Order) {
+				o.Delivery.TimeWindowExceptions = map[Date]TimeWindow{
+					NewDate(2015, time.December, 24): {StartSec: -1, EndSec: 3600},
+				}
+			},
+			want: `delivery.timeWindowExceptions["20151224"]: must not start before midnight`,
+		},
+		{
+			name: "tag in and out",
+			modify: func(o *Order) {
+				o.Delivery.TagsIn = []string{"frozen"}
+				o.Delivery.TagsOut = []string{"heavy", "frozen"}
+			},
+			want: `delivery.tagsOut[1]: tag "frozen" is also in tagsIn`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			o := validOrder()
+			tt.modify(&o)
+			err := o.Validate()
+			if tt.want == "" {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			c.Assert(err, qt.ErrorMatches, regexp.QuoteMeta(tt.want))
+			c.Assert(err.(ValidationErrors)[0].Index, qt.Equals, -1)
+		})
+	}
+}
+
+func TestValidateOrders(t *testing.T) {
+	c := qt.New(t)
+
+	first := validOrder()
+	second := validOrder()
+	second.Name = ""
+	second.Delivery.ServiceTimeSec = -1
+	third := validOrder()
+	third.Eligibility.Type = "soon"
+
+	err := ValidateOrders([]Order{first, second, third})
+	c.Assert(err, qt.ErrorMatches, "orders\\[1\\].name: is required; "+
+		"orders\\[1\\].delivery.serviceTimeSec: must not be negative; "+
+		"orders\\[2\\].eligibility.type: must be one of on, by, any; "+
+		"orders\\[2\\].eligibility.onDates: is only allowed when type is on")
+
+	byIndex := err.(ValidationErrors).ByIndex()
+	c.Assert(len(byIndex), qt.Equals, 2)
+	c.Assert(len(byIndex[1]), qt.Equals, 2)
+	c.Assert(byIndex[2][0].Field, qt.Equals, "eligibility.type")
+
+	c.Assert(ValidateOrders([]Order{first}), qt.IsNil)
+	c.Assert(ValidateOrders(nil), qt.IsNil)
+}
+
+func TestValidateOrdersFixtures(t *testing.T) {
+	for _, name := range []string{"orders-list.json", "orders-get.json", "routes-list-approved.json"} {
+		t.Run(name, func(t *testing.T) {
+			c := qt.New(t)
+			b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			c.Assert(err, qt.IsNil)
+			var r ordersResponse
+			c.Assert(json.Unmarshal(b, &r), qt.IsNil)
+			for id, o := range r.Orders {
+				c.Assert(o.Validate(), qt.IsNil, qt.Commentf("order %s", id))
+			}
+		})
+	}
+}
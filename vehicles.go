@@ -2,6 +2,56 @@ package workwave
 
 // Vehicle is a vehicle in WorkWave.
 type Vehicle struct {
-	ID   string `json:"id,omitempty"`
-	Name string `json:"externalId,omitempty"`
+	ID   string   `json:"id,omitempty"`
+	Name string   `json:"externalId,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	// Capacities are the maximum loads the vehicle carries.
+	Capacities Loads `json:"loadCapacities,omitempty"`
+}
+
+// HasTag reports whether v has the given tag.
+func (v Vehicle) HasTag(tag string) bool {
+	for _, t := range v.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// CanServe reports whether v is eligible for o: o must not be forced onto
+// another vehicle, and for each of its steps v must have all of the TagsIn
+// and none of the TagsOut.
+func (v Vehicle) CanServe(o Order) bool {
+	if o.ForceVehicleID != "" && string(o.ForceVehicleID) != v.ID {
+		return false
+	}
+	for _, s := range []*OrderStep{o.Pickup, o.Delivery} {
+		if s == nil {
+			continue
+		}
+		for _, tag := range s.TagsIn {
+			if !v.HasTag(tag) {
+				return false
+			}
+		}
+		for _, tag := range s.TagsOut {
+			if v.HasTag(tag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EligibleVehicles returns the vehicles which can serve o, in the order of
+// vehicles.
+func EligibleVehicles(o Order, vehicles []Vehicle) []Vehicle {
+	var eligible []Vehicle
+	for _, v := range vehicles {
+		if v.CanServe(o) {
+			eligible = append(eligible, v)
+		}
+	}
+	return eligible
 }
@@ -0,0 +1,75 @@
+package workwave
+
+import (
+	"encoding/json"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestVehicleIDJSON(t *testing.T) {
+	c := qt.New(t)
+
+	var o Order
+	c.Assert(json.Unmarshal([]byte(`{"forceVehicleId": "v1"}`), &o), qt.IsNil)
+	c.Assert(o.ForceVehicleID, qt.Equals, VehicleID("v1"))
+	c.Assert(json.Unmarshal([]byte(`{"forceVehicleId": null}`), &o), qt.IsNil)
+	c.Assert(o.ForceVehicleID, qt.Equals, VehicleID(""))
+	c.Assert(json.Unmarshal([]byte(`{"forceVehicleId": 1}`), &o), qt.ErrorMatches, "failed to decode vehicle ID: .*")
+
+	// Empty IDs are omitted from orders, and null when selected.
+	b, err := json.Marshal(Order{Name: "o"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"name":"o"}`)
+	b, err = json.Marshal(struct{ ID VehicleID }{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `{"ID":null}`)
+}
+
+func TestEligibleVehicles(t *testing.T) {
+	vehicles := []Vehicle{
+		{ID: "v1", Tags: []string{"fridge", "lift"}},
+		{ID: "v2", Tags: []string{"fridge", "hazmat"}},
+		{ID: "v3"},
+	}
+	tests := []struct {
+		name  string
+		order Order
+		want  []string
+	}{{
+		name:  "no constraints",
+		order: Order{Delivery: &OrderStep{}},
+		want:  []string{"v1", "v2", "v3"},
+	}, {
+		name:  "tags in",
+		order: Order{Delivery: &OrderStep{TagsIn: []string{"fridge"}}},
+		want:  []string{"v1", "v2"},
+	}, {
+		name:  "tags out",
+		order: Order{Delivery: &OrderStep{TagsIn: []string{"fridge"}, TagsOut: []string{"hazmat"}}},
+		want:  []string{"v1"},
+	}, {
+		name: "both steps",
+		order: Order{
+			Pickup:   &OrderStep{TagsOut: []string{"lift"}},
+			Delivery: &OrderStep{TagsIn: []string{"fridge"}},
+		},
+		want: []string{"v2"},
+	}, {
+		name:  "forced",
+		order: Order{ForceVehicleID: "v2", Delivery: &OrderStep{}},
+		want:  []string{"v2"},
+	}, {
+		name:  "forced onto an ineligible vehicle",
+		order: Order{ForceVehicleID: "v3", Delivery: &OrderStep{TagsIn: []string{"fridge"}}},
+	}}
+
+	for _, test := range tests {
+		c := qt.New(t)
+		var got []string
+		for _, v := range EligibleVehicles(test.order, vehicles) {
+			got = append(got, v.ID)
+		}
+		c.Check(got, qt.DeepEquals, test.want, qt.Commentf(test.name))
+	}
+}
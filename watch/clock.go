@@ -0,0 +1,99 @@
+package watch
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for it to pass. Watchers use the real clock
+// by default; tests can use a FakeClock instead.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// FakeClock is a Clock whose time only passes when it is advanced, which
+// makes watchers deterministic under test.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	until time.Time
+	c     chan time.Time
+}
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time of the clock.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel which receives the time of the clock once it has
+// been advanced by d.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- f.now
+		return c
+	}
+	f.waiters = append(f.waiters, fakeWaiter{until: f.now.Add(d), c: c})
+	f.cond.Broadcast()
+	return c
+}
+
+// Advance moves the clock forward by d, releasing those waiting for it.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	sort.SliceStable(f.waiters, func(a, b int) bool { return f.waiters[a].until.Before(f.waiters[b].until) })
+	n := 0
+	for ; n < len(f.waiters) && !f.waiters[n].until.After(f.now); n++ {
+		f.waiters[n].c <- f.now
+	}
+	f.waiters = f.waiters[n:]
+	f.cond.Broadcast()
+}
+
+// BlockUntil blocks until n callers are waiting on the clock, such as a
+// watcher waiting for its next poll.
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// Waiting returns the durations for which callers are waiting on the clock,
+// in increasing order.
+func (f *FakeClock) Waiting() []time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := make([]time.Duration, len(f.waiters))
+	for n, w := range f.waiters {
+		d[n] = w.until.Sub(f.now)
+	}
+	sort.Slice(d, func(a, b int) bool { return d[a] < d[b] })
+	return d
+}
@@ -0,0 +1,47 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+var epoch = time.Date(2015, time.December, 4, 8, 0, 0, 0, time.UTC)
+
+func TestFakeClock(t *testing.T) {
+	c := qt.New(t)
+	clock := NewFakeClock(epoch)
+	c.Assert(clock.Now(), qt.Equals, epoch)
+
+	a := clock.After(time.Minute)
+	b := clock.After(time.Second)
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{time.Second, time.Minute})
+
+	clock.Advance(30 * time.Second)
+	c.Assert(<-b, qt.Equals, epoch.Add(30*time.Second))
+	select {
+	case <-a:
+		c.Fatal("fired early")
+	default:
+	}
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{30 * time.Second})
+
+	clock.Advance(30 * time.Second)
+	c.Assert(<-a, qt.Equals, epoch.Add(time.Minute))
+	c.Assert(clock.Waiting(), qt.HasLen, 0)
+
+	// Waits which are already over fire immediately.
+	c.Assert(<-clock.After(0), qt.Equals, epoch.Add(time.Minute))
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	c := qt.New(t)
+	clock := NewFakeClock(epoch)
+	done := make(chan time.Time)
+	go func() { done <- <-clock.After(time.Second) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	c.Assert(<-done, qt.Equals, epoch.Add(time.Second))
+}
@@ -0,0 +1,291 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// OrderStore keeps the snapshot of orders an OrderWatcher compares each poll
+// with. Implementations must be safe for concurrent use.
+type OrderStore interface {
+	// Orders returns the stored orders by ID.
+	Orders(ctx context.Context) (map[string]workwave.Order, error)
+	// Put stores the given orders, replacing those with the same IDs.
+	Put(ctx context.Context, orders ...workwave.Order) error
+	// Delete removes the orders with the given IDs.
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// MemoryOrderStore is an OrderStore which keeps orders in memory.
+type MemoryOrderStore struct {
+	mu     sync.Mutex
+	orders map[string]workwave.Order
+}
+
+// NewMemoryOrderStore creates an empty MemoryOrderStore.
+func NewMemoryOrderStore() *MemoryOrderStore {
+	return &MemoryOrderStore{orders: make(map[string]workwave.Order)}
+}
+
+// Orders returns a copy of the stored orders.
+func (s *MemoryOrderStore) Orders(ctx context.Context) (map[string]workwave.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make(map[string]workwave.Order, len(s.orders))
+	for id, o := range s.orders {
+		orders[id] = o
+	}
+	return orders, nil
+}
+
+// Put stores the given orders.
+func (s *MemoryOrderStore) Put(ctx context.Context, orders ...workwave.Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range orders {
+		s.orders[o.ID] = o
+	}
+	return nil
+}
+
+// Delete removes the orders with the given IDs.
+func (s *MemoryOrderStore) Delete(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.orders, id)
+	}
+	return nil
+}
+
+// OrderEventType is the type of an order watcher event.
+type OrderEventType int
+
+// Order event types.
+const (
+	// OrderCreated orders are not in the snapshot. With an empty snapshot,
+	// the first poll reports every order as created.
+	OrderCreated OrderEventType = iota
+	// OrderUpdated orders differ from their snapshot.
+	OrderUpdated
+	// OrderDeleted orders are in the snapshot but no longer listed.
+	OrderDeleted
+	// OrderPollFailed events report that orders could not be listed or
+	// compared with the snapshot. The watcher retries with backoff.
+	OrderPollFailed
+)
+
+func (t OrderEventType) String() string {
+	switch t {
+	case OrderCreated:
+		return "created"
+	case OrderUpdated:
+		return "updated"
+	case OrderDeleted:
+		return "deleted"
+	case OrderPollFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("OrderEventType(%d)", int(t))
+}
+
+// OrderEvent is a change observed by an OrderWatcher.
+type OrderEvent struct {
+	Type OrderEventType
+	// Time is the time of the poll, according to the watcher's clock.
+	Time time.Time
+	// Order is the order as listed, or its snapshot for OrderDeleted.
+	Order workwave.Order
+	// Changes are the changed fields of an updated order.
+	Changes []workwave.FieldChange
+	// Err is set for OrderPollFailed.
+	Err error
+}
+
+// OrderWatcher polls the orders of a territory, and reports the orders which
+// were created, updated or deleted since they were last seen.
+//
+// Changes which were already received through WorkWave callbacks are passed
+// to Acknowledge, and are then not reported again. An OrderWatcher must not be
+// run more than once at a time.
+type OrderWatcher struct {
+	Orders workwave.OrdersService
+	// Input selects the orders which are watched.
+	Input workwave.OrdersListInput
+	// Store defaults to a MemoryOrderStore.
+	Store   OrderStore
+	Polling Polling
+
+	once sync.Once
+	// mu keeps acknowledgements from interleaving with the comparison and
+	// storage of a poll.
+	mu sync.Mutex
+	// seq counts acknowledgements, and acked is the seq of the latest
+	// acknowledgement of each order since the start of the last poll, whose
+	// listing may be older.
+	seq   uint64
+	acked map[string]uint64
+}
+
+func (w *OrderWatcher) store() OrderStore {
+	w.once.Do(func() {
+		if w.Store == nil {
+			w.Store = NewMemoryOrderStore()
+		}
+	})
+	return w.Store
+}
+
+// Acknowledge records that the given orders were created or updated, so that
+// a poll finding them in this state does not report them.
+func (w *OrderWatcher) Acknowledge(ctx context.Context, orders ...workwave.Order) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, o := range orders {
+		w.ack(o.ID)
+	}
+	return w.store().Put(ctx, orders...)
+}
+
+// AcknowledgeDeleted records that the orders with the given IDs were
+// deleted, so that a poll not finding them does not report them.
+func (w *OrderWatcher) AcknowledgeDeleted(ctx context.Context, ids ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range ids {
+		w.ack(id)
+	}
+	return w.store().Delete(ctx, ids...)
+}
+
+// ack records an acknowledgement of the order with the given ID. It must be
+// called with mu held.
+func (w *OrderWatcher) ack(id string) {
+	if w.acked == nil {
+		w.acked = make(map[string]uint64)
+	}
+	w.seq++
+	w.acked[id] = w.seq
+}
+
+// Run polls until ctx is done, calling fn with the events of each poll in
+// order of order ID. It returns the error of ctx.
+func (w *OrderWatcher) Run(ctx context.Context, fn func(OrderEvent)) error {
+	p := w.Polling.withDefaults()
+	return p.run(ctx, func(ctx context.Context) error {
+		now := p.Clock.Now()
+		events, err := w.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fn(OrderEvent{Type: OrderPollFailed, Time: now, Err: err})
+			return err
+		}
+		for _, e := range events {
+			e.Time = now
+			fn(e)
+		}
+		return nil
+	})
+}
+
+// Watch runs the watcher in a goroutine, sending its events on the returned
+// channel, which is closed once ctx is done.
+func (w *OrderWatcher) Watch(ctx context.Context) <-chan OrderEvent {
+	events := make(chan OrderEvent)
+	go func() {
+		defer close(events)
+		w.Run(ctx, func(e OrderEvent) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return events
+}
+
+// poll lists the orders and compares them with the snapshot, which is then
+// updated. Orders acknowledged while they were being listed are skipped, as
+// the listing may predate their acknowledged state.
+func (w *OrderWatcher) poll(ctx context.Context) ([]OrderEvent, error) {
+	w.mu.Lock()
+	start := w.seq
+	for id, seq := range w.acked {
+		if seq <= start {
+			delete(w.acked, id)
+		}
+	}
+	w.mu.Unlock()
+
+	orders, err := w.Orders.List(ctx, w.Input)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list orders")
+	}
+	workwave.SortOrdersByID(orders)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stale := func(id string) bool {
+		return w.acked[id] > start
+	}
+	store := w.store()
+	snapshot, err := store.Orders(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load order snapshot")
+	}
+
+	var events []OrderEvent
+	var changed []workwave.Order
+	listed := make(map[string]bool, len(orders))
+	for _, o := range orders {
+		listed[o.ID] = true
+		if stale(o.ID) {
+			continue
+		}
+		old, ok := snapshot[o.ID]
+		if !ok {
+			events = append(events, OrderEvent{Type: OrderCreated, Order: o})
+			changed = append(changed, o)
+			continue
+		}
+		changes, err := workwave.DiffOrder(old, o)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compare order %s", o.ID)
+		}
+		if len(changes) > 0 {
+			events = append(events, OrderEvent{Type: OrderUpdated, Order: o, Changes: changes})
+			changed = append(changed, o)
+		}
+	}
+
+	var deleted []string
+	for id := range snapshot {
+		if !listed[id] && !stale(id) {
+			deleted = append(deleted, id)
+		}
+	}
+	for _, id := range deleted {
+		events = append(events, OrderEvent{Type: OrderDeleted, Order: snapshot[id]})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Order.ID < events[j].Order.ID })
+
+	if len(changed) > 0 {
+		if err := store.Put(ctx, changed...); err != nil {
+			return nil, errors.Wrap(err, "failed to store order snapshot")
+		}
+	}
+	if len(deleted) > 0 {
+		if err := store.Delete(ctx, deleted...); err != nil {
+			return nil, errors.Wrap(err, "failed to store order snapshot")
+		}
+	}
+	return events, nil
+}
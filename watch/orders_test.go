@@ -0,0 +1,189 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// fakeOrders answers each call to List with the next of its results.
+type fakeOrders struct {
+	workwave.OrdersService
+	fakeCalls
+
+	// listing is called during each call to List, if set.
+	listing func()
+}
+
+func newFakeOrders(results ...fakeResult) *fakeOrders {
+	return &fakeOrders{fakeCalls: fakeCalls{results: results}}
+}
+
+func (f *fakeOrders) List(ctx context.Context, i workwave.OrdersListInput) ([]workwave.Order, error) {
+	if f.listing != nil {
+		f.listing()
+	}
+	v, err := f.call(i)
+	orders, _ := v.([]workwave.Order)
+	return orders, err
+}
+
+func watchedOrder(id, name string) workwave.Order {
+	return workwave.Order{
+		ID:          id,
+		Name:        name,
+		Eligibility: workwave.Eligibility{Type: workwave.EligibilityAny},
+		Delivery:    &workwave.OrderStep{Location: workwave.Location{Address: "710 7th Ave"}},
+	}
+}
+
+func TestOrderWatcher(t *testing.T) {
+	c := qt.New(t)
+	clock := NewFakeClock(epoch)
+	svc := newFakeOrders(
+		fakeResult{value: []workwave.Order{watchedOrder("o2", "Order 2"), watchedOrder("o1", "Order 1")}},
+		fakeResult{err: errors.New("HTTP 503 error")},
+		fakeResult{value: []workwave.Order{watchedOrder("o1", "Order 1b"), watchedOrder("o3", "Order 3")}},
+		// Changes already received through callbacks are not reported.
+		fakeResult{value: []workwave.Order{watchedOrder("o1", "Order 1c")}},
+	)
+	w := &OrderWatcher{
+		Orders:  svc,
+		Input:   workwave.OrdersListInput{TerritoryID: "territory"},
+		Polling: Polling{Interval: time.Minute, Jitter: -1, Clock: clock},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := w.Watch(ctx)
+
+	e := <-events
+	c.Assert(e.Type, qt.Equals, OrderCreated)
+	c.Assert(e.Order.ID, qt.Equals, "o1")
+	c.Assert(e.Time, qt.Equals, epoch)
+	c.Assert((<-events).Order.ID, qt.Equals, "o2")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	e = <-events
+	c.Assert(e.Type, qt.Equals, OrderPollFailed)
+	c.Assert(e.Err, qt.ErrorMatches, "failed to list orders: HTTP 503 error")
+
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Minute)
+	e = <-events
+	c.Assert(e.Type, qt.Equals, OrderUpdated)
+	c.Assert(e.Order.Name, qt.Equals, "Order 1b")
+	c.Assert(e.Changes, qt.DeepEquals, []workwave.FieldChange{
+		{Field: "name", Old: json.RawMessage(`"Order 1"`), New: json.RawMessage(`"Order 1b"`)},
+	})
+	// Deletions are in order of ID along with the other events.
+	e = <-events
+	c.Assert(e.Type, qt.Equals, OrderDeleted)
+	c.Assert(e.Order.Name, qt.Equals, "Order 2")
+	e = <-events
+	c.Assert(e.Type, qt.Equals, OrderCreated)
+	c.Assert(e.Order.ID, qt.Equals, "o3")
+
+	clock.BlockUntil(1)
+	c.Assert(w.Acknowledge(ctx, watchedOrder("o1", "Order 1c")), qt.IsNil)
+	c.Assert(w.AcknowledgeDeleted(ctx, "o3"), qt.IsNil)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+
+	cancel()
+	_, ok := <-events
+	c.Assert(ok, qt.Equals, false)
+
+	snapshot, err := w.Store.Orders(context.Background())
+	c.Assert(err, qt.IsNil)
+	c.Assert(snapshot, qt.DeepEquals, map[string]workwave.Order{"o1": watchedOrder("o1", "Order 1c")})
+}
+
+func TestOrderWatcherAcknowledgeWhileListing(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	svc := newFakeOrders(
+		fakeResult{value: []workwave.Order{watchedOrder("o1", "Order 1"), watchedOrder("o2", "Order 2")}},
+		fakeResult{value: []workwave.Order{watchedOrder("o1", "Order 1"), watchedOrder("o2", "Order 2")}},
+	)
+	w := &OrderWatcher{Orders: svc}
+	c.Assert(w.Acknowledge(ctx, watchedOrder("o1", "Order 1"), watchedOrder("o2", "Order 2")), qt.IsNil)
+
+	// Callbacks arrive while the orders are being listed, and the listing
+	// does not include them yet.
+	svc.listing = func() {
+		c.Check(w.Acknowledge(ctx, watchedOrder("o1", "Order 1b"), watchedOrder("o3", "Order 3")), qt.IsNil)
+		c.Check(w.AcknowledgeDeleted(ctx, "o2"), qt.IsNil)
+	}
+	events, err := w.poll(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(events, qt.HasLen, 0)
+	snapshot, err := w.Store.Orders(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(snapshot, qt.DeepEquals, map[string]workwave.Order{
+		"o1": watchedOrder("o1", "Order 1b"),
+		"o3": watchedOrder("o3", "Order 3"),
+	})
+
+	// Later listings are compared as usual.
+	svc.listing = nil
+	events, err = w.poll(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(events, qt.HasLen, 3)
+	c.Assert(events[0].Type, qt.Equals, OrderUpdated)
+	c.Assert(events[1].Type, qt.Equals, OrderCreated)
+	c.Assert(events[1].Order.ID, qt.Equals, "o2")
+	c.Assert(events[2].Type, qt.Equals, OrderDeleted)
+	c.Assert(w.acked, qt.HasLen, 0)
+}
+
+type failingStore struct {
+	*MemoryOrderStore
+}
+
+func (failingStore) Orders(ctx context.Context) (map[string]workwave.Order, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestOrderWatcherStoreError(t *testing.T) {
+	c := qt.New(t)
+	w := &OrderWatcher{
+		Orders:  newFakeOrders(fakeResult{value: []workwave.Order{watchedOrder("o1", "Order 1")}}),
+		Store:   failingStore{NewMemoryOrderStore()},
+		Polling: Polling{Clock: NewFakeClock(epoch)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var events []OrderEvent
+	err := w.Run(ctx, func(e OrderEvent) {
+		events = append(events, e)
+		cancel()
+	})
+	c.Assert(err, qt.Equals, context.Canceled)
+	c.Assert(events, qt.HasLen, 1)
+	c.Assert(events[0].Type.String(), qt.Equals, "failed")
+	c.Assert(events[0].Err, qt.ErrorMatches, "failed to load order snapshot: store unavailable")
+}
+
+func TestMemoryOrderStore(t *testing.T) {
+	c := qt.New(t)
+	ctx := context.Background()
+	s := NewMemoryOrderStore()
+	c.Assert(s.Put(ctx, watchedOrder("o1", "Order 1"), watchedOrder("o2", "Order 2")), qt.IsNil)
+	c.Assert(s.Delete(ctx, "o2", "missing"), qt.IsNil)
+
+	orders, err := s.Orders(ctx)
+	c.Assert(err, qt.IsNil)
+	c.Assert(orders, qt.DeepEquals, map[string]workwave.Order{"o1": watchedOrder("o1", "Order 1")})
+
+	// The returned orders are a copy.
+	delete(orders, "o1")
+	orders, _ = s.Orders(ctx)
+	c.Assert(orders, qt.HasLen, 1)
+}
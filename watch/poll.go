@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultInterval   = 30 * time.Second
+	defaultMaxBackoff = 10 * time.Minute
+	defaultJitter     = 0.1
+)
+
+// Polling configures how often a watcher polls WorkWave.
+type Polling struct {
+	// Interval is the time between polls. Defaults to 30 seconds.
+	Interval time.Duration
+	// MaxBackoff caps the time between polls after consecutive failures,
+	// which doubles with each failure. Defaults to 10 minutes.
+	MaxBackoff time.Duration
+	// Jitter randomly varies the time between polls by up to this fraction,
+	// to spread the load of many watchers. Defaults to 0.1, and negative
+	// values disable it.
+	Jitter float64
+	// Clock defaults to the real clock.
+	Clock Clock
+	// Rand is the source of jitter. Defaults to a source seeded with the
+	// time.
+	Rand *rand.Rand
+}
+
+func (p Polling) withDefaults() Polling {
+	if p.Interval <= 0 {
+		p.Interval = defaultInterval
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultMaxBackoff
+	}
+	if p.MaxBackoff < p.Interval {
+		p.MaxBackoff = p.Interval
+	}
+	if p.Jitter == 0 {
+		p.Jitter = defaultJitter
+	}
+	if p.Clock == nil {
+		p.Clock = realClock{}
+	}
+	if p.Rand == nil {
+		p.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return p
+}
+
+// run calls poll immediately and then after every interval, backing off
+// after failures, until ctx is done. The defaults of p must have been applied.
+func (p Polling) run(ctx context.Context, poll func(context.Context) error) error {
+	failures := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := poll(ctx); err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.Clock.After(p.delay(failures)):
+		}
+	}
+}
+
+// delay returns the time to wait before the next poll.
+func (p Polling) delay(failures int) time.Duration {
+	d := p.Interval
+	for n := 0; n < failures && d < p.MaxBackoff; n++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration(float64(d) * p.Jitter * (2*p.Rand.Float64() - 1))
+	}
+	return d
+}
@@ -0,0 +1,52 @@
+package watch
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPollingDelay(t *testing.T) {
+	c := qt.New(t)
+	p := Polling{Interval: 10 * time.Second, MaxBackoff: 35 * time.Second, Jitter: -1}.withDefaults()
+	for failures, want := range []time.Duration{10 * time.Second, 20 * time.Second, 35 * time.Second, 35 * time.Second} {
+		c.Assert(p.delay(failures), qt.Equals, want)
+	}
+	c.Assert(p.delay(1000), qt.Equals, 35*time.Second)
+
+	p = Polling{Interval: 10 * time.Second, Rand: rand.New(rand.NewSource(1))}.withDefaults()
+	c.Assert(p.MaxBackoff, qt.Equals, defaultMaxBackoff)
+	for n := 0; n < 100; n++ {
+		d := p.delay(0)
+		c.Assert(d >= 9*time.Second && d <= 11*time.Second, qt.Equals, true, qt.Commentf("delay %s", d))
+	}
+}
+
+// fakeCalls answers each call of a fake service with the next of its
+// results, repeating the last one, and records the inputs of the calls.
+type fakeCalls struct {
+	mu      sync.Mutex
+	inputs  []interface{}
+	results []fakeResult
+}
+
+// fakeResult is the value, such as a []workwave.Route, and the error
+// returned by a call.
+type fakeResult struct {
+	value interface{}
+	err   error
+}
+
+func (f *fakeCalls) call(input interface{}) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.inputs = append(f.inputs, input)
+	r := f.results[0]
+	if len(f.results) > 1 {
+		f.results = f.results[1:]
+	}
+	return r.value, r.err
+}
@@ -0,0 +1,168 @@
+// Package watch polls WorkWave for changes, as an alternative to callbacks
+// where WorkWave cannot reach a callback URL.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// EventType is the type of a watcher event.
+type EventType int
+
+// Event types.
+const (
+	// RouteAdded routes were not listed by the previous poll. The first poll
+	// reports every route as added.
+	RouteAdded EventType = iota
+	// RouteChanged routes have a new revision, or have otherwise changed.
+	RouteChanged
+	// RouteRemoved routes are no longer listed.
+	RouteRemoved
+	// PollFailed events report that routes could not be listed. The watcher
+	// retries with backoff.
+	PollFailed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case RouteAdded:
+		return "added"
+	case RouteChanged:
+		return "changed"
+	case RouteRemoved:
+		return "removed"
+	case PollFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is a change observed by a Watcher.
+type Event struct {
+	Type EventType
+	// Time is the time of the poll, according to the watcher's clock.
+	Time time.Time
+	// Route is the route as listed, or as last listed for RouteRemoved.
+	Route workwave.Route
+	// Diff is set for RouteChanged.
+	Diff *workwave.RouteDiff
+	// Err is set for PollFailed.
+	Err error
+}
+
+// Watcher polls the current routes of a territory, and reports the routes
+// which were added, changed or removed since the previous poll. A Watcher
+// must not be run more than once at a time.
+type Watcher struct {
+	Routes      workwave.RoutesService
+	TerritoryID string
+	// Date is the date of the routes as yyyyMMdd. It defaults to the date
+	// in Location at the time of each poll, according to the clock.
+	Date string
+	// Location is the time zone of the territory. Defaults to UTC.
+	Location *time.Location
+	// Diff configures the diffs of changed routes. The zero value reports
+	// every arrival shift.
+	Diff    workwave.DiffOptions
+	Polling Polling
+
+	routes map[string]workwave.Route
+}
+
+// Run polls until ctx is done, calling fn with the events of each poll in
+// order of route ID. It returns the error of ctx.
+func (w *Watcher) Run(ctx context.Context, fn func(Event)) error {
+	p := w.Polling.withDefaults()
+	return p.run(ctx, func(ctx context.Context) error {
+		now := p.Clock.Now()
+		routes, err := w.Routes.ListCurrent(ctx, workwave.RoutesListCurrentInput{
+			TerritoryID: w.TerritoryID,
+			Date:        w.date(now),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fn(Event{Type: PollFailed, Time: now, Err: errors.Wrap(err, "failed to list routes")})
+			return err
+		}
+		for _, e := range w.update(routes) {
+			e.Time = now
+			fn(e)
+		}
+		return nil
+	})
+}
+
+// date returns the date of the routes listed at now.
+func (w *Watcher) date(now time.Time) string {
+	if w.Date != "" {
+		return w.Date
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return workwave.DateOf(now.In(loc)).String()
+}
+
+// Watch runs the watcher in a goroutine, sending its events on the returned
+// channel, which is closed once ctx is done.
+func (w *Watcher) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		w.Run(ctx, func(e Event) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return events
+}
+
+// update records the listed routes, returning the events since the previous
+// update.
+func (w *Watcher) update(routes []workwave.Route) []Event {
+	listed := make(map[string]workwave.Route, len(routes))
+	for _, r := range routes {
+		listed[r.ID] = r
+	}
+
+	var events []Event
+	for _, id := range sortedKeys(listed) {
+		r := listed[id]
+		old, ok := w.routes[id]
+		if !ok {
+			events = append(events, Event{Type: RouteAdded, Route: r})
+			continue
+		}
+		if d := w.Diff.DiffRoutes(old, r); old.Revision != r.Revision || !d.Empty() {
+			events = append(events, Event{Type: RouteChanged, Route: r, Diff: &d})
+		}
+	}
+	for _, id := range sortedKeys(w.routes) {
+		if _, ok := listed[id]; !ok {
+			events = append(events, Event{Type: RouteRemoved, Route: w.routes[id]})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Route.ID < events[j].Route.ID })
+	w.routes = listed
+	return events
+}
+
+func sortedKeys(routes map[string]workwave.Route) []string {
+	keys := make([]string, 0, len(routes))
+	for k := range routes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
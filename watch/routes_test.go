@@ -0,0 +1,162 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	workwave "github.com/tkh/go-workwave"
+)
+
+// fakeRoutes answers each call to ListCurrent with the next of its results.
+type fakeRoutes struct {
+	workwave.RoutesService
+	fakeCalls
+}
+
+func newFakeRoutes(results ...fakeResult) *fakeRoutes {
+	return &fakeRoutes{fakeCalls: fakeCalls{results: results}}
+}
+
+func (f *fakeRoutes) ListCurrent(ctx context.Context, i workwave.RoutesListCurrentInput) ([]workwave.Route, error) {
+	v, err := f.call(i)
+	routes, _ := v.([]workwave.Route)
+	return routes, err
+}
+
+func watchedRoute(id string, revision int, arrival workwave.SecOfDay) workwave.Route {
+	return workwave.Route{
+		ID:       id,
+		Revision: revision,
+		Steps: []workwave.RouteStep{
+			{Type: workwave.StepDeparture},
+			{Type: workwave.StepDelivery, OrderID: "o-" + id, ArrivalSec: arrival},
+		},
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	c := qt.New(t)
+	clock := NewFakeClock(epoch)
+	changed := watchedRoute("r1", 2, 33000)
+	svc := newFakeRoutes(
+		fakeResult{value: []workwave.Route{watchedRoute("r2", 1, 30000), watchedRoute("r1", 1, 30000)}},
+		fakeResult{err: errors.New("HTTP 503 error")},
+		fakeResult{err: errors.New("HTTP 503 error")},
+		fakeResult{value: []workwave.Route{changed}},
+		fakeResult{value: []workwave.Route{changed}},
+	)
+	w := &Watcher{
+		Routes:      svc,
+		TerritoryID: "territory",
+		Date:        "20151204",
+		Polling:     Polling{Interval: time.Minute, Jitter: -1, Clock: clock},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := w.Watch(ctx)
+
+	e := <-events
+	c.Assert(e.Type, qt.Equals, RouteAdded)
+	c.Assert(e.Route.ID, qt.Equals, "r1")
+	c.Assert(e.Time, qt.Equals, epoch)
+	e = <-events
+	c.Assert(e.Route.ID, qt.Equals, "r2")
+
+	// Failures back off.
+	clock.BlockUntil(1)
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{time.Minute})
+	clock.Advance(time.Minute)
+	e = <-events
+	c.Assert(e.Type, qt.Equals, PollFailed)
+	c.Assert(e.Err, qt.ErrorMatches, "failed to list routes: HTTP 503 error")
+
+	clock.BlockUntil(1)
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{2 * time.Minute})
+	clock.Advance(2 * time.Minute)
+	c.Assert((<-events).Type, qt.Equals, PollFailed)
+
+	clock.BlockUntil(1)
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{4 * time.Minute})
+	clock.Advance(4 * time.Minute)
+	e = <-events
+	c.Assert(e.Type, qt.Equals, RouteChanged)
+	c.Assert(e.Time, qt.Equals, epoch.Add(7*time.Minute))
+	c.Assert(e.Diff.OldRevision, qt.Equals, 1)
+	c.Assert(e.Diff.Steps, qt.DeepEquals, []workwave.StepChange{{
+		Kind:       workwave.StepArrivalShifted,
+		Type:       workwave.StepDelivery,
+		OrderID:    "o-r1",
+		OldIndex:   1,
+		NewIndex:   1,
+		OldArrival: 30000,
+		NewArrival: 33000,
+		Shift:      50 * time.Minute,
+	}})
+	e = <-events
+	c.Assert(e.Type, qt.Equals, RouteRemoved)
+	c.Assert(e.Route.ID, qt.Equals, "r2")
+
+	// Success resets the backoff, and unchanged routes are not reported.
+	clock.BlockUntil(1)
+	c.Assert(clock.Waiting(), qt.DeepEquals, []time.Duration{time.Minute})
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+
+	cancel()
+	_, ok := <-events
+	c.Assert(ok, qt.Equals, false)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	c.Assert(svc.inputs, qt.HasLen, 5)
+	c.Assert(svc.inputs[0], qt.DeepEquals, workwave.RoutesListCurrentInput{TerritoryID: "territory", Date: "20151204"})
+}
+
+func TestWatcherEventOrder(t *testing.T) {
+	c := qt.New(t)
+	w := &Watcher{}
+	w.update([]workwave.Route{watchedRoute("r1", 1, 30000), watchedRoute("r2", 1, 30000)})
+
+	// Removed routes are in order of ID along with the other events.
+	var got []string
+	for _, e := range w.update([]workwave.Route{watchedRoute("r3", 1, 30000), watchedRoute("r1", 2, 30000)}) {
+		got = append(got, e.Type.String()+" "+e.Route.ID)
+	}
+	c.Assert(got, qt.DeepEquals, []string{"changed r1", "removed r2", "added r3"})
+}
+
+func TestWatcherRun(t *testing.T) {
+	c := qt.New(t)
+	clock := NewFakeClock(epoch)
+	w := &Watcher{
+		Routes:  newFakeRoutes(fakeResult{value: []workwave.Route{watchedRoute("r1", 1, 30000)}}),
+		Polling: Polling{Clock: clock},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var events []Event
+	err := w.Run(ctx, func(e Event) {
+		events = append(events, e)
+		cancel()
+	})
+	c.Assert(err, qt.Equals, context.Canceled)
+	c.Assert(events, qt.HasLen, 1)
+	c.Assert(events[0].Type.String(), qt.Equals, "added")
+}
+
+func TestWatcherDefaultDate(t *testing.T) {
+	c := qt.New(t)
+	w := &Watcher{}
+	c.Assert(w.date(epoch), qt.Equals, "20151204")
+	c.Assert(w.date(epoch.Add(16*time.Hour)), qt.Equals, "20151205")
+
+	w.Location = time.FixedZone("CST", -6*60*60)
+	c.Assert(w.date(epoch.Add(16*time.Hour)), qt.Equals, "20151204")
+
+	w.Date = "20151201"
+	c.Assert(w.date(epoch), qt.Equals, "20151201")
+}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"runtime"
 	"time"
 
@@ -28,13 +29,41 @@ type Client struct {
 	baseURL *url.URL
 	apiKey  string
 
-	Callback CallbackService
-	Orders   OrdersService
-	Routes   RoutesService
+	strictDecoding bool
+	strictEnums    bool
+
+	Callback  CallbackService
+	Execution ExecutionService
+	GPS       GPSService
+	Orders    OrdersService
+	Routes    RoutesService
+}
+
+// Option configures a Client created by New.
+type Option func(*Client)
+
+// WithStrictDecoding makes JSON fields which are not modelled errors when
+// decoding responses. By default they are kept in the Extra field of the value
+// they belong to, and encoded again with it, so that fields added to the API
+// later are not lost when a value is sent back. Strict decoding lets tests
+// catch API changes.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithStrictEnums makes enum values which are not known, such as a StepType
+// added to the API later, errors when encoding requests and decoding
+// responses. By default they are passed through as is.
+func WithStrictEnums() Option {
+	return func(c *Client) {
+		c.strictEnums = true
+	}
 }
 
 // New creates a new WorkWave API client with the given API key for authentication.
-func New(apiKey string) (*Client, error) {
+func New(apiKey string, opts ...Option) (*Client, error) {
 	baseURL, err := url.Parse(apiBaseURL)
 	if err != nil {
 		return nil, err
@@ -44,8 +73,13 @@ func New(apiKey string) (*Client, error) {
 		baseURL: baseURL,
 		apiKey:  apiKey,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
 
 	c.Callback = &callbackService{client: c}
+	c.Execution = &executionService{client: c}
+	c.GPS = &gpsService{client: c}
 	c.Orders = &ordersService{client: c}
 	c.Routes = &routesService{client: c}
 
@@ -82,6 +116,12 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, body inter
 
 	buf := new(bytes.Buffer)
 	if body != nil {
+		if c.strictEnums {
+			err = checkEnums(reflect.ValueOf(body))
+			if err != nil {
+				return nil, err
+			}
+		}
 		err = json.NewEncoder(buf).Encode(body)
 		if err != nil {
 			return nil, err
@@ -101,12 +141,33 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, body inter
 	return req, nil
 }
 
+// NewUploadRequest prepares an API HTTP request using the given method and path
+// whose body is streamed from r with the given media type, rather than being
+// converted to JSON and buffered in memory.
+func (c *Client) NewUploadRequest(ctx context.Context, method, path, mediaType string, r io.Reader) (*http.Request, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, u.String(), r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add HTTP headers
+	req.Header.Add("X-WorkWave-Key", c.apiKey)
+	req.Header.Add("User-Agent", agentString)
+	req.Header.Add("Content-Type", mediaType)
+	req.Header.Add("Accept", contentType)
+	return req, nil
+}
+
 // Do submits an HTTP request with the Client's HTTP client.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*http.Response, error) {
-	req = req.WithContext(ctx)
-	res, err := c.client.Do(req)
+	res, err := c.send(ctx, req)
 	if err != nil {
-		return nil, err
+		return res, err
 	}
 
 	defer func() {
@@ -115,11 +176,6 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*htt
 		}
 	}()
 
-	err = checkResponse(res)
-	if err != nil {
-		return res, err
-	}
-
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
 			_, err = io.Copy(w, res.Body)
@@ -131,11 +187,44 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*htt
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to decode JSON")
 			}
+			err = c.checkDecoded(v)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode JSON")
+			}
 		}
 	}
 	return res, err
 }
 
+// send submits an HTTP request, returning the response for its body to be
+// read by the caller, who must close it. Responses with an unsuccessful status
+// are returned with an error, and their body already closed.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(res); err != nil {
+		res.Body.Close()
+		return res, err
+	}
+	return res, nil
+}
+
+// checkDecoded checks a value decoded from a response against the options of
+// the client.
+func (c *Client) checkDecoded(v interface{}) error {
+	if c.strictDecoding {
+		if err := checkExtra(reflect.ValueOf(v)); err != nil {
+			return err
+		}
+	}
+	if c.strictEnums {
+		return checkEnums(reflect.ValueOf(v))
+	}
+	return nil
+}
+
 func checkResponse(res *http.Response) error {
 	sc := res.StatusCode
 	if sc >= 200 && sc <= 200 {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -112,6 +113,22 @@ func TestNewRequest(t *testing.T) {
 	})
 }
 
+func TestNewUploadRequest(t *testing.T) {
+	c := qt.New(t)
+	client, err := New("api-key")
+	c.Assert(err, qt.IsNil)
+
+	req, err := client.NewUploadRequest(ctx, http.MethodPost, "/path/", "image/png", strings.NewReader("data"))
+	c.Assert(err, qt.IsNil)
+	// Headers
+	c.Assert(req.Header.Get("X-WorkWave-Key"), qt.Equals, "api-key")
+	c.Assert(req.Header.Get("Content-Type"), qt.Equals, "image/png")
+	c.Assert(req.Header.Get("Accept"), qt.Equals, contentType)
+	// Body
+	rBody, _ := ioutil.ReadAll(req.Body)
+	c.Assert(string(rBody), qt.Equals, "data")
+}
+
 func TestDo(t *testing.T) {
 	setup()
 	defer teardown()